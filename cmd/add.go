@@ -10,27 +10,103 @@ import (
 
 // addCmd represents the add command
 var addCmd = &cobra.Command{
-	Use:   "add [path]",
-	Short: "Add a link to the project",
+	Use:   "add [path...]",
+	Short: "Add links to the project",
 	Long: `Add a link to the project configuration.
 
 This command will:
-- Add the specified path as a link in the .lnkr.toml configuration
+- Add the specified paths as links in the .lnkr.toml configuration
 - If recursive flag is set, it will also add all subdirectories and files
-- Update the configuration file with the new link entries`,
-	Args: cobra.ExactArgs(1),
+- Update the configuration file with the new link entries
+
+Paths can also be read from stdin with --stdin (one per line) or -0
+(NUL-separated, e.g. from find -print0). All paths are added as one batch:
+the configuration and the ignore file are written once, and a summary of
+added, skipped and failed paths is printed.
+
+Paths tracked by git (read from the git index) are refused, because excluding
+them has no effect: use --untrack to remove them from the index (this runs
+'git rm --cached' and needs git installed), or --force to add them anyway.
+
+With --interactive, files not tracked by git (or, with --from-remote, files that
+only exist in the remote) are listed in a picker: move with the arrow keys or j/k,
+select with space, change the link type with t, and add the selection with enter.`,
+	Args:              cobra.ArbitraryArgs,
+	ValidArgsFunction: completeAddPaths,
 	Run: func(cmd *cobra.Command, args []string) {
 		recursive, _ := cmd.Flags().GetBool("recursive")
 		symbolic, _ := cmd.Flags().GetBool("symbolic")
+		asCopy, _ := cmd.Flags().GetBool("copy")
+		encrypted, _ := cmd.Flags().GetBool("encrypted")
 		fromRemote, _ := cmd.Flags().GetBool("from-remote")
-		path := args[0]
+		force, _ := cmd.Flags().GetBool("force")
+		untrack, _ := cmd.Flags().GetBool("untrack")
+		interactive, _ := cmd.Flags().GetBool("interactive")
+
+		if interactive && (len(args) > 0 || cmd.Flags().Changed("stdin") || cmd.Flags().Changed("null")) {
+			fmt.Fprintf(os.Stderr, "Error: --interactive does not take paths\n")
+			os.Exit(1)
+		}
+
+		paths, err := pathArgs(cmd, args)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to read paths from stdin: %v\n", err)
+			os.Exit(1)
+		}
+		if !interactive && len(paths) == 0 {
+			fmt.Fprintf(os.Stderr, "Error: requires at least one path (or --stdin, --interactive)\n")
+			os.Exit(1)
+		}
+
+		userConfig, err := lnkr.LoadUserConfig()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if (symbolic && asCopy) || (symbolic && encrypted) || (asCopy && encrypted) {
+			fmt.Fprintf(os.Stderr, "Error: only one of --symbolic, --copy and --encrypted can be used\n")
+			os.Exit(1)
+		}
+		if force && untrack {
+			fmt.Fprintf(os.Stderr, "Error: --force and --untrack cannot be used together\n")
+			os.Exit(1)
+		}
+
+		// An explicit --symbolic, --copy or --encrypted flag wins over LNKR_LINK_TYPE and the user configuration
+		linkType := lnkr.ResolveLinkType(userConfig)
+		if cmd.Flags().Changed("symbolic") || cmd.Flags().Changed("copy") || cmd.Flags().Changed("encrypted") {
+			linkType = lnkr.LinkTypeHard
+			if symbolic {
+				linkType = lnkr.LinkTypeSymbolic
+			} else if asCopy {
+				linkType = lnkr.LinkTypeCopy
+			} else if encrypted {
+				linkType = lnkr.LinkTypeEncrypted
+			}
+		}
 
-		linkType := lnkr.LinkTypeHard
-		if symbolic {
-			linkType = lnkr.LinkTypeSymbolic
+		opts := lnkr.AddOptions{
+			Recursive:  recursive,
+			LinkType:   linkType,
+			FromRemote: fromRemote,
+			Force:      force,
+			Untrack:    untrack,
+			Scan:       userConfig.ScanSecrets,
+		}
+		// An explicit --scan or --scan=false wins over scan_secrets in the user configuration
+		if cmd.Flags().Changed("scan") {
+			opts.Scan, _ = cmd.Flags().GetBool("scan")
+		}
+		if interactive {
+			if err := journaled(func() error { return lnkr.AddInteractive(opts) }); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
 		}
 
-		if err := lnkr.Add(path, recursive, linkType, fromRemote); err != nil {
+		if err := journaled(func() error { return lnkr.Add(paths, opts) }); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
@@ -42,6 +118,13 @@ func init() {
 
 	// Add flags
 	addCmd.Flags().BoolP("recursive", "r", false, "Add recursively (include subdirectories and files)")
-	addCmd.Flags().BoolP("symbolic", "s", false, "Create symbolic link (default: hard link)")
+	addCmd.Flags().BoolP("symbolic", "s", false, "Create symbolic link (default: hard link, or link_type from user config; use --symbolic=false to force hard link)")
+	addCmd.Flags().Bool("copy", false, "Create an independent copy instead of a link")
+	addCmd.Flags().Bool("encrypted", false, "Keep the remote encrypted and a decrypted 0600 copy locally")
 	addCmd.Flags().Bool("from-remote", false, "Use remote directory as base for relative paths")
+	addCmd.Flags().Bool("force", false, "Add paths that are tracked by git (excluding them has no effect)")
+	addCmd.Flags().BoolP("interactive", "i", false, "Pick the files to add, and their link types, from a list")
+	addCmd.Flags().Bool("untrack", false, "Remove paths that are tracked by git from the index (runs git rm --cached, so git must be installed)")
+	addCmd.Flags().Bool("scan", false, "Scan files for credentials and high-entropy strings (default: scan_secrets from user config)")
+	addPathInputFlags(addCmd)
 }
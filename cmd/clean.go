@@ -16,9 +16,37 @@ var cleanCmd = &cobra.Command{
 
 This command will:
 - Remove .lnkr.toml configuration file if it exists
-- Remove .lnkr.toml entry from .git/info/exclude`,
+- Remove .lnkr.toml entry from .git/info/exclude
+
+With --all, the project is fully torn down:
+- Every link is removed from the filesystem (or, with --restore, replaced by
+  a regular copy of the remote content)
+- The whole LNKR section, including its markers, is removed from .git/info/exclude
+- .lnkr.toml is removed
+- With --prune-remote, empty directories in the remote directory are removed
+
+Links that are not in sync with the remote make --all refuse unless --force is set.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		if err := lnkr.Clean(); err != nil {
+		all, _ := cmd.Flags().GetBool("all")
+		restore, _ := cmd.Flags().GetBool("restore")
+		pruneRemote, _ := cmd.Flags().GetBool("prune-remote")
+		force, _ := cmd.Flags().GetBool("force")
+
+		if !all && (restore || pruneRemote || force) {
+			fmt.Fprintf(os.Stderr, "Error: --restore, --prune-remote and --force require --all\n")
+			os.Exit(1)
+		}
+
+		if all {
+			opts := lnkr.CleanAllOptions{Restore: restore, PruneRemote: pruneRemote, Force: force}
+			if err := journaled(func() error { return lnkr.CleanAll(opts) }); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		if err := journaled(func() error { return lnkr.Clean() }); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
@@ -27,4 +55,8 @@ This command will:
 
 func init() {
 	rootCmd.AddCommand(cleanCmd)
+	cleanCmd.Flags().Bool("all", false, "Fully tear down the project (unlink all links and remove the LNKR section)")
+	cleanCmd.Flags().Bool("restore", false, "With --all, replace links with regular copies instead of removing them")
+	cleanCmd.Flags().Bool("prune-remote", false, "With --all, remove empty directories in the remote directory")
+	cleanCmd.Flags().Bool("force", false, "With --all, tear down links that are not in sync with the remote")
 }
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/longkey1/lnkr/internal/lnkr"
+	"github.com/spf13/cobra"
+)
+
+// completeLinkPaths completes any number of configured link paths, skipping the
+// ones already on the command line
+func completeLinkPaths(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return lnkr.CompleteLinkPaths(toComplete, args), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeLinkPath completes a single configured link path
+func completeLinkPath(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeLinkPaths(cmd, args, toComplete)
+}
+
+// completeAddPaths completes files under the remote directory with --from-remote,
+// and local files otherwise
+func completeAddPaths(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if fromRemote, _ := cmd.Flags().GetBool("from-remote"); !fromRemote {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+
+	paths := lnkr.CompleteRemotePaths(toComplete)
+	directive := cobra.ShellCompDirectiveNoFileComp
+	// Do not add a space after a directory so that completion can continue inside it
+	if slices.ContainsFunc(paths, func(p string) bool { return strings.HasSuffix(p, string(os.PathSeparator)) }) {
+		directive |= cobra.ShellCompDirectiveNoSpace
+	}
+	return paths, directive
+}
+
+// completeSnapshotRestore completes the snapshot ID, then the link paths to restore
+func completeSnapshotRestore(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		return lnkr.CompleteSnapshotIDs(toComplete), cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+	}
+	return lnkr.CompleteLinkPaths(toComplete, args[1:]), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeConfigKey completes the key of config get and unset
+func completeConfigKey(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return lnkr.CompleteConfigKeys(toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeConfigKeyValue completes the key and, for settings with a fixed set of
+// values, the value of config set
+func completeConfigKeyValue(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	switch len(args) {
+	case 0:
+		return lnkr.CompleteConfigKeys(toComplete), cobra.ShellCompDirectiveNoFileComp
+	case 1:
+		if values := lnkr.CompleteConfigValues(args[0]); values != nil {
+			return values, cobra.ShellCompDirectiveNoFileComp
+		}
+		// Paths such as local, remote and git_exclude_path
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+// registerFlagValues completes a flag from a fixed list of values
+func registerFlagValues(cmd *cobra.Command, flag string, values []string) {
+	cmd.RegisterFlagCompletionFunc(flag, cobra.FixedCompletions(values, cobra.ShellCompDirectiveNoFileComp))
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/longkey1/lnkr/internal/lnkr"
+	"github.com/spf13/cobra"
+)
+
+// configCmd represents the config command
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage the .lnkr.toml configuration",
+	Long: `Manage the .lnkr.toml configuration file.
+
+Keys:
+- version           schema version (read-only)
+- local             local directory (absolute path)
+- remote            remote directory (absolute path)
+- git_exclude_path  path of the git exclude file
+- ignore_backend    ignore file kept in sync (git-exclude, gitignore, hgignore, dockerignore or none)
+- link.<path>.type  type of the link for <path> (hard, symbolic or copy)`,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:               "get [key]",
+	Short:             "Print the value of a setting",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeConfigKey,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := lnkr.ConfigGet(args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set [key] [value]",
+	Short: "Validate and store the value of a setting",
+	Long: `Validate and store the value of a setting.
+
+Changing link.<path>.type only updates the configuration; use 'lnkr convert'
+to also transform the link on disk.`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeConfigKeyValue,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := journaled(func() error { return lnkr.ConfigSet(args[0], args[1]) }); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:               "unset [key]",
+	Short:             "Clear an optional setting",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeConfigKey,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := journaled(func() error { return lnkr.ConfigUnset(args[0]) }); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all settings",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := lnkr.ConfigList(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var configEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Edit .lnkr.toml in $VISUAL or $EDITOR",
+	Long:  `Open .lnkr.toml in $VISUAL or $EDITOR (default: vi) and validate it after the editor exits.`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := journaled(func() error { return lnkr.ConfigEdit() }); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check .lnkr.toml for problems",
+	Long: `Check .lnkr.toml for problems.
+
+Every link path must be relative, normalized, unique and of a known type.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := lnkr.ValidateConfigFile(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var configMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Upgrade .lnkr.toml to the current schema version",
+	Long: `Upgrade .lnkr.toml to the current schema version.
+
+Older files are upgraded in memory (with a warning) whenever they are loaded.
+This command rewrites the file so the warning goes away.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := journaled(func() error { return lnkr.MigrateConfig() }); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configUnsetCmd)
+	configCmd.AddCommand(configListCmd)
+	configCmd.AddCommand(configEditCmd)
+	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configMigrateCmd)
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/longkey1/lnkr/internal/lnkr"
+	"github.com/spf13/cobra"
+)
+
+var convertCmd = &cobra.Command{
+	Use:   "convert [path]",
+	Short: "Change the type of a link in place",
+	Long: `Change the type of a link in the .lnkr.toml configuration and on disk.
+
+This command will:
+- Check that the local link is in sync with the remote, so nothing is lost
+- Replace the local link with the new type (files are swapped atomically)
+- Expand a symbolic directory link into one hard link per file, or collapse
+  per-file hard links of a directory into a single symbolic link or copy
+- Update the configuration file with the new link entries`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeLinkPath,
+	Run: func(cmd *cobra.Command, args []string) {
+		to, _ := cmd.Flags().GetString("to")
+		if err := journaled(func() error { return lnkr.Convert(args[0], to) }); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(convertCmd)
+	convertCmd.Flags().String("to", "", fmt.Sprintf("New link type (%s)", strings.Join(lnkr.LinkTypes, "|")))
+	convertCmd.MarkFlagRequired("to")
+	registerFlagValues(convertCmd, "to", lnkr.LinkTypes)
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/longkey1/lnkr/internal/lnkr"
+	"github.com/spf13/cobra"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [path...]",
+	Short: "Compare local and remote content of links",
+	Long: `Compare the local and remote content of links defined in the .lnkr.toml configuration file.
+
+This command will:
+- Print a unified diff between Local/<path> and Remote/<path> for text files
+- Report binary files that differ without printing their content
+- Summarize the differences of directories (e.g. symbolic directory links)
+
+Without paths, only links that are not in sync (drifted) are compared.`,
+	ValidArgsFunction: completeLinkPaths,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := lnkr.Diff(args); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+}
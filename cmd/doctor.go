@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/longkey1/lnkr/internal/lnkr"
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the environment and configuration for problems",
+	Long: `Check the environment and configuration for problems and print actionable fixes.
+
+This command will check:
+- LNKR_* environment variables (e.g. LNKR_REMOTE_DEPTH must be a positive integer)
+- Syntax and values of the user configuration and .lnkr.toml
+- That local is the project root
+- That remote exists and is writable
+- That hard links can be created between local and remote
+- That the git exclude path is inside a real git directory`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := lnkr.Doctor(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
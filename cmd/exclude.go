@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/longkey1/lnkr/internal/lnkr"
+	"github.com/spf13/cobra"
+)
+
+// excludeCmd represents the exclude command
+var excludeCmd = &cobra.Command{
+	Use:   "exclude",
+	Short: "Manage the LNKR section of the ignore file",
+	Long: `Manage the LNKR section (### LNKR STA ... ### LNKR END) of the ignore file.
+
+The ignore file is chosen by ignore_backend in .lnkr.toml: the git exclude file
+(git-exclude, the default), .gitignore, .hgignore, .dockerignore, or none.
+
+The section holds .lnkr.toml and every link path from the .lnkr.toml configuration.
+It is regenerated automatically whenever the configuration changes.`,
+}
+
+var excludeSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Regenerate the LNKR section from .lnkr.toml",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := journaled(func() error { return lnkr.ExcludeSync() }); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var excludeShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the entries of the LNKR section",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := lnkr.ExcludeShow(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var excludeCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Report missing and stale entries in the LNKR section",
+	Long:  `Report entries missing from, or stale in, the LNKR section. Exits with status 1 if it is out of sync.`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := lnkr.ExcludeCheck(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(excludeCmd)
+	excludeCmd.AddCommand(excludeSyncCmd)
+	excludeCmd.AddCommand(excludeShowCmd)
+	excludeCmd.AddCommand(excludeCheckCmd)
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/longkey1/lnkr/internal/lnkr"
+	"github.com/spf13/cobra"
+)
+
+var hookForce bool
+
+// hookCmd represents the hook command
+var hookCmd = &cobra.Command{
+	Use:   "hook",
+	Short: "Manage the git pre-commit hook",
+	Long: `Manage the git pre-commit hook that refuses to commit files managed by lnkr.
+
+The hook runs 'lnkr hook run', which checks the staged files against .lnkr.toml
+and the configured links (including files inside directory links) and blocks the
+commit when any of them is staged. lnkr must be on the PATH of git.`,
+}
+
+var hookInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install the pre-commit hook",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := journaled(func() error { return lnkr.HookInstall(hookForce) }); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var hookUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove the pre-commit hook installed by lnkr",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := journaled(lnkr.HookUninstall); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var hookRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Check the staged files (body of the pre-commit hook)",
+	Long:  `Check the staged files against the configured links. Exits with status 1 if any lnkr-managed file is staged.`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := lnkr.HookRun(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(hookCmd)
+	hookCmd.AddCommand(hookInstallCmd)
+	hookCmd.AddCommand(hookUninstallCmd)
+	hookCmd.AddCommand(hookRunCmd)
+	hookInstallCmd.Flags().BoolVar(&hookForce, "force", false, "Replace a pre-commit hook that was not installed by lnkr")
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"strconv"
 
 	"github.com/longkey1/lnkr/internal/lnkr"
 	"github.com/spf13/cobra"
@@ -14,6 +13,10 @@ var (
 	remoteDir        string
 	withCreateRemote bool
 	gitExcludePath   string
+	ignoreBackend    string
+	initFromRemote   bool
+	remoteLayout     string
+	remoteTemplate   string
 )
 
 // initCmd represents the init command
@@ -24,7 +27,13 @@ var initCmd = &cobra.Command{
 
 This command will:
 - Create .lnkr.toml configuration file if it doesn't exist
-- Add .lnkr.toml to .git/info/exclude to prevent it from being tracked`,
+- Add .lnkr.toml to .git/info/exclude to prevent it from being tracked
+  (or to the ignore file of the backend selected with --ignore-backend)
+- Mirror .lnkr.toml into the remote directory
+
+With --from-remote, the .lnkr.toml mirrored in the remote directory is restored
+locally (with local rewritten to the current directory) and the links are
+created from the remote side. Use this on a fresh clone.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		// Get current directory
 		currentDir, err := os.Getwd()
@@ -33,44 +42,65 @@ This command will:
 			os.Exit(1)
 		}
 
-		// Get the number of depth to go up from environment variable, default to DefaultRemoteDepth
-		depthStr := os.Getenv("LNKR_REMOTE_DEPTH")
-		depth := lnkr.DefaultRemoteDepth // default value
-		if depthStr != "" {
-			if parsedDepth, err := strconv.Atoi(depthStr); err == nil && parsedDepth > 0 {
-				depth = parsedDepth
-			}
+		userConfig, err := lnkr.LoadUserConfig()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
 		}
 
-		// Get base directory for remote
-		baseDir := os.Getenv("LNKR_REMOTE_ROOT")
-		if baseDir == "" {
-			// Default to $HOME/.config/lnkr
-			homeDir, err := os.UserHomeDir()
+		// Get remote directory from flag or default
+		if remoteDir == "" {
+			// Get the number of depth to go up (LNKR_REMOTE_DEPTH > user config > DefaultRemoteDepth)
+			depth, err := lnkr.ResolveRemoteDepth(userConfig)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error: failed to get home directory: %v\n", err)
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 				os.Exit(1)
 			}
-			baseDir = filepath.Join(homeDir, ".config", "lnkr")
-		}
 
-		// Get remote directory from flag or default
-		if remoteDir == "" {
-			// Use lnkr package function to get default remote path
-			remoteDir = lnkr.GetDefaultRemotePath(currentDir, baseDir, depth)
-		} else {
-			// If remoteDir is specified, make it absolute path based on baseDir
-			if !filepath.IsAbs(remoteDir) {
-				remoteDir = filepath.Join(baseDir, remoteDir)
+			// Get base directory for remote (LNKR_REMOTE_ROOT > user config > $XDG_CONFIG_HOME/lnkr)
+			baseDir, err := lnkr.ResolveRemoteRoot(userConfig)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+
+			// Derive the default remote path using the selected layout
+			layout, err := lnkr.ResolveRemoteLayout(remoteLayout, userConfig)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
 			}
+			template := lnkr.ResolveRemoteTemplate(remoteTemplate, userConfig)
+			remoteDir, err = lnkr.GetRemotePath(layout, currentDir, baseDir, depth, template)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: failed to derive remote path: %v\n", err)
+				os.Exit(1)
+			}
+		} else if !filepath.IsAbs(remoteDir) {
+			// A relative remote directory is resolved against the remote root
+			baseDir, err := lnkr.ResolveRemoteRoot(userConfig)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			remoteDir = filepath.Join(baseDir, remoteDir)
 		}
 
 		// Set default git exclude path if not specified
-		if gitExcludePath == "" {
-			gitExcludePath = lnkr.GitExcludePath
+		// (when restoring from remote, the mirrored value is kept)
+		if gitExcludePath == "" && !initFromRemote {
+			gitExcludePath = lnkr.ResolveGitExcludePath(userConfig)
+		}
+
+		if initFromRemote {
+			if err := journaled(func() error { return lnkr.InitFromRemote(remoteDir, gitExcludePath, ignoreBackend) }); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
 		}
 
-		if err := lnkr.Init(remoteDir, withCreateRemote, gitExcludePath); err != nil {
+		if err := journaled(func() error { return lnkr.Init(remoteDir, withCreateRemote, gitExcludePath, ignoreBackend) }); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
@@ -79,7 +109,14 @@ This command will:
 
 func init() {
 	rootCmd.AddCommand(initCmd)
-	initCmd.Flags().StringVarP(&remoteDir, "remote", "r", "", "Remote directory to save in .lnkr.toml (if not specified, uses LNKR_REMOTE_ROOT/project-name or parent-dir/current-dir based on LNKR_REMOTE_DEPTH)")
+	initCmd.Flags().StringVarP(&remoteDir, "remote", "r", "", "Remote directory to save in .lnkr.toml (if not specified, derived under LNKR_REMOTE_ROOT using the remote layout)")
 	initCmd.Flags().BoolVar(&withCreateRemote, "with-create-remote", false, "Create remote directory if it does not exist")
-	initCmd.Flags().StringVar(&gitExcludePath, "git-exclude-path", "", "Custom path for git exclude file (default: .git/info/exclude)")
+	initCmd.Flags().StringVar(&gitExcludePath, "git-exclude-path", "", "Custom path for git exclude file (default: LNKR_GIT_EXCLUDE_PATH, user config, or .git/info/exclude)")
+	initCmd.Flags().StringVar(&ignoreBackend, "ignore-backend", "", "Ignore file kept in sync with the links: git-exclude|gitignore|hgignore|dockerignore|none (default: git-exclude)")
+	initCmd.Flags().StringVar(&remoteLayout, "remote-layout", "", "How to derive the default remote path: depth|git-remote|path-hash|template (default: LNKR_REMOTE_LAYOUT, user config, or depth)")
+	initCmd.Flags().StringVar(&remoteTemplate, "remote-template", "", "Template for the template layout, e.g. {host}/{owner}/{repo} (placeholders: host, owner, repo, dir, parent, path, hash)")
+	initCmd.Flags().BoolVar(&initFromRemote, "from-remote", false, "Restore .lnkr.toml from the remote directory and create links from remote")
+	initCmd.MarkFlagDirname("remote")
+	registerFlagValues(initCmd, "ignore-backend", lnkr.IgnoreBackends)
+	registerFlagValues(initCmd, "remote-layout", lnkr.RemoteLayouts)
 }
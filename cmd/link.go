@@ -16,7 +16,8 @@ var linkCmd = &cobra.Command{
 	Long:  `Create hard links, symbolic links, or directories based on the .lnkr.toml configuration file.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		fromRemote, _ := cmd.Flags().GetBool("from-remote")
-		if err := lnkr.CreateLinks(fromRemote); err != nil {
+		force, _ := cmd.Flags().GetBool("force")
+		if err := journaled(func() error { return lnkr.CreateLinks(fromRemote, force) }); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
@@ -26,4 +27,5 @@ var linkCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(linkCmd)
 	linkCmd.Flags().Bool("from-remote", false, "Use remote directory as base for link local paths")
+	linkCmd.Flags().Bool("force", false, "Re-render templates over local edits")
 }
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/longkey1/lnkr/internal/lnkr"
+	"github.com/spf13/cobra"
+)
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List links in .lnkr.toml configuration",
+	Long: `List the links defined in the .lnkr.toml configuration file with their size,
+remote size and which side is the source of truth.
+
+Source of truth:
+- shared    local and remote are the same file (or identical copies)
+- remote    local is a symbolic link to remote, or only remote exists
+- local     remote is a symbolic link to local, or only local exists
+- diverged  local and remote are different files
+- missing   neither local nor remote exists
+
+Use --format null to pipe paths to xargs -0.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		tree, _ := cmd.Flags().GetBool("tree")
+		linkType, _ := cmd.Flags().GetString("type")
+		format, _ := cmd.Flags().GetString("format")
+
+		opts := lnkr.ListOptions{Tree: tree, Type: linkType, Format: format}
+		if err := lnkr.List(opts); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(listCmd)
+	listCmd.Flags().Bool("tree", false, "Group entries by directory")
+	listCmd.Flags().String("type", "", fmt.Sprintf("Only list links of this type (%s)", strings.Join(lnkr.LinkTypes, "|")))
+	listCmd.Flags().String("format", lnkr.ListFormatPlain, fmt.Sprintf("Output format (%s)", strings.Join(lnkr.ListFormats, "|")))
+	registerFlagValues(listCmd, "type", lnkr.LinkTypes)
+	registerFlagValues(listCmd, "format", lnkr.ListFormats)
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// addPathInputFlags registers the flags reading paths from stdin
+func addPathInputFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("stdin", false, "Also read paths from stdin, one per line")
+	cmd.Flags().BoolP("null", "0", false, "Read NUL-separated paths from stdin (implies --stdin)")
+}
+
+// pathArgs returns the paths given as arguments and, with --stdin or --null, the
+// paths read from stdin
+func pathArgs(cmd *cobra.Command, args []string) ([]string, error) {
+	fromStdin, _ := cmd.Flags().GetBool("stdin")
+	null, _ := cmd.Flags().GetBool("null")
+	if !fromStdin && !null {
+		return args, nil
+	}
+	return readPaths(args, os.Stdin, null)
+}
+
+// readPaths returns the paths given as arguments followed by the paths read from r,
+// one per line or, with null, separated by NUL characters (as printed by find -print0)
+func readPaths(args []string, r io.Reader, null bool) ([]string, error) {
+	paths := append([]string{}, args...)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	if null {
+		scanner.Split(scanNull)
+	}
+	for scanner.Scan() {
+		path := scanner.Text()
+		if !null {
+			path = strings.TrimSuffix(path, "\r")
+		}
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths, scanner.Err()
+}
+
+// scanNull is a bufio.SplitFunc splitting input at NUL characters
+func scanNull(data []byte, atEOF bool) (int, []byte, error) {
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
@@ -9,13 +9,32 @@ import (
 )
 
 var removeCmd = &cobra.Command{
-	Use:   "remove [path]",
-	Short: "Remove a link from the project",
-	Long:  `Remove a link (and its subdirectories) from the .lnkr.toml configuration by path.`,
-	Args:  cobra.ExactArgs(1),
+	Use:   "remove [path...]",
+	Short: "Remove links from the project",
+	Long: `Remove links (and their subdirectories) from the .lnkr.toml configuration by path.
+
+This command will:
+- Remove the matching link entries from the .lnkr.toml configuration
+- Remove the matching paths from the LNKR section of .git/info/exclude
+- With --unlink, also remove the links from the filesystem (links that are
+  not in sync with the remote are kept)
+
+Paths can also be read from stdin with --stdin (one per line) or -0
+(NUL-separated). All paths are removed as one batch.`,
+	Args:              cobra.ArbitraryArgs,
+	ValidArgsFunction: completeLinkPaths,
 	Run: func(cmd *cobra.Command, args []string) {
-		path := args[0]
-		if err := lnkr.Remove(path); err != nil {
+		unlink, _ := cmd.Flags().GetBool("unlink")
+		paths, err := pathArgs(cmd, args)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to read paths from stdin: %v\n", err)
+			os.Exit(1)
+		}
+		if len(paths) == 0 {
+			fmt.Fprintf(os.Stderr, "Error: requires at least one path (or --stdin)\n")
+			os.Exit(1)
+		}
+		if err := journaled(func() error { return lnkr.Remove(paths, unlink) }); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
@@ -24,4 +43,6 @@ var removeCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(removeCmd)
+	removeCmd.Flags().Bool("unlink", false, "Also remove the links from the filesystem")
+	addPathInputFlags(removeCmd)
 }
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/longkey1/lnkr/internal/lnkr"
+	"github.com/spf13/cobra"
+)
+
+var snapshotMessage string
+
+// snapshotCmd represents the snapshot command
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Manage snapshots of the linked files",
+	Long: `Manage snapshots of the linked files, stored in .lnkr-history in the remote directory.
+
+A snapshot keeps the content of every remote file of the links, and of the local
+files that do not share their content with the remote (copies and drifted files).
+The decrypted local files of encrypted links are never stored. Contents are stored
+once per distinct content, so repeated snapshots only take the space of the files
+that changed.
+
+A snapshot is taken automatically before 'lnkr convert' changes a file on disk,
+before 'lnkr clean --all', before 'lnkr snapshot restore', before 'lnkr unlink --force'
+removes edited copies or decrypted files, and before 'lnkr sync --force' or
+'lnkr sync --from-remote' overwrites a file. Decrypted files of encrypted links are
+never stored in snapshots; 'lnkr undo' restores them.`,
+}
+
+var snapshotCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Take a snapshot of the linked files",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := lnkr.SnapshotCreate(snapshotMessage); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var snapshotListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the snapshots",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := lnkr.SnapshotList(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var snapshotRestoreCmd = &cobra.Command{
+	Use:   "restore <id> [path...]",
+	Short: "Restore the linked files from a snapshot",
+	Long: `Restore the files of a snapshot in place. With paths, only the links at or under
+them are restored.
+
+Files are rewritten rather than replaced, so hard links keep sharing the restored
+content. The current state is snapshotted first, so a restore can be undone by
+restoring that snapshot.`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeSnapshotRestore,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := journaled(func() error { return lnkr.SnapshotRestore(args[0], args[1:]) }); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(snapshotCmd)
+	snapshotCmd.AddCommand(snapshotCreateCmd)
+	snapshotCmd.AddCommand(snapshotListCmd)
+	snapshotCmd.AddCommand(snapshotRestoreCmd)
+	snapshotCreateCmd.Flags().StringVarP(&snapshotMessage, "message", "m", "", "Describe the snapshot")
+}
@@ -9,11 +9,14 @@ import (
 )
 
 var statusCmd = &cobra.Command{
-	Use:   "status",
+	Use:   "status [path...]",
 	Short: "Show status of links in .lnkr.toml configuration",
-	Long:  `Show the status of all links defined in the .lnkr.toml configuration file.`,
+	Long: `Show the status of all links defined in the .lnkr.toml configuration file.
+
+With paths, only the links at or under them are shown.`,
+	ValidArgsFunction: completeLinkPaths,
 	Run: func(cmd *cobra.Command, args []string) {
-		if err := lnkr.Status(); err != nil {
+		if err := lnkr.Status(args); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
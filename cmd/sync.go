@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/longkey1/lnkr/internal/lnkr"
+	"github.com/spf13/cobra"
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync [path...]",
+	Short: "Encrypt local edits of encrypted links into the remote",
+	Long: `Encrypt the local edits of encrypted links back into the remote.
+
+This command will:
+- Re-encrypt every decrypted local file that differs from the remote
+- Skip files whose remote changed after the local copy (use --force to overwrite)
+- With --from-remote, decrypt the remote over local files instead, skipping
+  files with local edits unless --force is set
+
+A snapshot is taken before a forced or --from-remote sync overwrites a file.
+With paths, only the encrypted links at or under them are synced.`,
+	ValidArgsFunction: completeLinkPaths,
+	Run: func(cmd *cobra.Command, args []string) {
+		fromRemote, _ := cmd.Flags().GetBool("from-remote")
+		force, _ := cmd.Flags().GetBool("force")
+		opts := lnkr.SyncOptions{FromRemote: fromRemote, Force: force}
+		if err := journaled(func() error { return lnkr.Sync(args, opts) }); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+	syncCmd.Flags().Bool("from-remote", false, "Decrypt the remote over the local files")
+	syncCmd.Flags().Bool("force", false, "Overwrite files that changed after the other side")
+}
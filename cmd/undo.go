@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/longkey1/lnkr/internal/lnkr"
+	"github.com/spf13/cobra"
+)
+
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Revert the last lnkr command",
+	Long: `Revert the most recent command that changed the project in the current directory.
+
+Every command that changes .lnkr.toml, the ignore file or the links records what it
+changed in a journal, kept in the git directory (or in $XDG_STATE_HOME/lnkr outside
+git). The journal holds copies of the changed files; decrypted files of encrypted
+links are stored encrypted. This command will:
+- Check that nothing changed after the command (use --force to undo anyway)
+- Restore .lnkr.toml, the ignore file and the local and remote files as they were
+- Remove the entry from the journal, so running it again reverts the command before`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		force, _ := cmd.Flags().GetBool("force")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		opts := lnkr.UndoOptions{Force: force, DryRun: dryRun}
+		if err := lnkr.Undo(opts); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// journaled runs a command that changes the project, recording it for 'lnkr undo'
+func journaled(run func() error) error {
+	return lnkr.Journaled(strings.Join(append([]string{"lnkr"}, os.Args[1:]...), " "), run)
+}
+
+func init() {
+	rootCmd.AddCommand(undoCmd)
+	undoCmd.Flags().Bool("force", false, "Undo even if paths changed after the command")
+	undoCmd.Flags().Bool("dry-run", false, "Print what would be restored without changing anything")
+}
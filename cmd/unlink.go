@@ -8,12 +8,20 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var unlinkForce bool
+
 var unlinkCmd = &cobra.Command{
-	Use:   "unlink",
+	Use:   "unlink [path...]",
 	Short: "Remove links based on .lnkr.toml configuration",
-	Long:  `Remove hard links, symbolic links, or directories based on the .lnkr.toml configuration file.`,
+	Long: `Remove hard links, symbolic links, or directories based on the .lnkr.toml configuration file.
+
+With paths, only the links at or under them are removed. The links stay in the
+configuration; use 'lnkr remove' to drop them from it. Copies whose content differs
+from the remote, and decrypted files with edits that are not encrypted yet, are kept
+unless --force is set. A snapshot is taken before --force removes them.`,
+	ValidArgsFunction: completeLinkPaths,
 	Run: func(cmd *cobra.Command, args []string) {
-		if err := lnkr.Unlink(); err != nil {
+		if err := journaled(func() error { return lnkr.Unlink(args, unlinkForce) }); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
@@ -22,4 +30,5 @@ var unlinkCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(unlinkCmd)
+	unlinkCmd.Flags().BoolVar(&unlinkForce, "force", false, "Also remove copies and decrypted files with local edits")
 }
@@ -2,10 +2,12 @@ module github.com/longkey1/lnkr
 
 go 1.24.3
 
-require github.com/spf13/cobra v1.9.1
+require (
+	github.com/BurntSushi/toml v1.5.0
+	github.com/spf13/cobra v1.9.1
+)
 
 require (
-	github.com/BurntSushi/toml v1.5.0 // indirect
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
 	github.com/spf13/pflag v1.0.6 // indirect
 )
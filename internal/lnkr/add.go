@@ -1,20 +1,42 @@
 package lnkr
 
 import (
+	"bytes"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"sort"
+	"strings"
 )
 
-func Add(path string, recursive bool, linkType string, fromRemote bool) error {
-	if linkType != LinkTypeHard && linkType != LinkTypeSymbolic {
-		return fmt.Errorf("invalid link type: %s. Must be '%s' or '%s'", linkType, LinkTypeHard, LinkTypeSymbolic)
-	}
+// AddOptions controls how Add selects and records paths
+type AddOptions struct {
+	// Recursive adds every file under a directory
+	Recursive bool
+	// LinkType is the type of the new links
+	LinkType string
+	// FromRemote resolves paths against the remote directory
+	FromRemote bool
+	// Force adds paths that are tracked by git, with a warning
+	Force bool
+	// Untrack removes tracked paths from the git index (git rm --cached)
+	Untrack bool
+	// Scan checks the new files for credentials and high-entropy strings
+	Scan bool
+}
 
-	// Check if path is absolute
-	if filepath.IsAbs(path) {
-		return fmt.Errorf("absolute path is not allowed: %s. Please use relative path", path)
+// Add adds links for the given paths, saving the configuration and updating the
+// ignore file once for the whole batch
+func Add(paths []string, opts AddOptions) error {
+	if !isValidLinkType(opts.LinkType) {
+		return fmt.Errorf("invalid link type: %s. Must be one of: %s", opts.LinkType, strings.Join(LinkTypes, ", "))
+	}
+	// The index is read natively, but removing paths from it is left to git
+	if opts.Untrack {
+		if _, err := exec.LookPath("git"); err != nil {
+			return fmt.Errorf("--untrack requires the git command, which was not found in PATH")
+		}
 	}
 
 	config, err := loadConfig()
@@ -22,81 +44,186 @@ func Add(path string, recursive bool, linkType string, fromRemote bool) error {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 
-	// Determine base directory for relative paths
-	var baseDir string
+	userConfig, err := LoadUserConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load user configuration: %w", err)
+	}
+
+	baseDir, err := addBaseDir(config, opts.FromRemote)
+	if err != nil {
+		return err
+	}
+
+	// Check existing links to avoid duplicates
+	existing := make(map[string]struct{})
+	for _, link := range config.Links {
+		existing[link.Path] = struct{}{}
+	}
+
+	var links []Link
+	skipped, failed := 0, 0
+	for _, path := range paths {
+		targets, err := collectAddTargets(path, baseDir, opts, existing, userConfig.Ignore)
+		if err != nil {
+			// A single path fails as a whole, like before batches existed
+			if len(paths) == 1 {
+				return err
+			}
+			fmt.Printf("Error adding %s: %v\n", path, err)
+			failed++
+			continue
+		}
+		if len(targets) == 0 {
+			if len(paths) > 1 {
+				fmt.Printf("Already added, skipping: %s\n", path)
+			}
+			skipped++
+			continue
+		}
+		for _, t := range targets {
+			existing[t] = struct{}{}
+			links = append(links, Link{Path: t, Type: opts.LinkType})
+		}
+	}
+
+	if len(links) == 0 {
+		if failed == 0 {
+			fmt.Println("No new paths to add.")
+		}
+	} else if err := addLinks(config, links, opts); err != nil {
+		return err
+	}
+
+	if len(paths) > 1 {
+		fmt.Printf("Summary: %d added, %d skipped, %d failed\n", len(links), skipped, failed)
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to add %d path(s)", failed)
+	}
+	return nil
+}
+
+// addBaseDir returns the directory relative paths are resolved against
+func addBaseDir(config *Config, fromRemote bool) (string, error) {
 	if fromRemote {
 		if config.Remote == "" {
-			return fmt.Errorf("remote directory not configured. Run 'lnkr init --remote <path>' first")
-		}
-		baseDir = config.Remote
-	} else {
-		if config.Local == "" {
-			return fmt.Errorf("local directory not configured. Run 'lnkr init --local <path>' first")
+			return "", fmt.Errorf("remote directory not configured. Run 'lnkr init --remote <path>' first")
 		}
-		baseDir = config.Local
+		return config.Remote, nil
+	}
+	if config.Local == "" {
+		return "", fmt.Errorf("local directory not configured. Run 'lnkr init --local <path>' first")
+	}
+	return config.Local, nil
+}
+
+// collectAddTargets returns the new link paths for a path, expanding directories
+// into files where the link type requires it
+func collectAddTargets(path, baseDir string, opts AddOptions, existing map[string]struct{}, ignore []string) ([]string, error) {
+	recursive, linkType := opts.Recursive, opts.LinkType
+
+	// Check if path is absolute
+	if filepath.IsAbs(path) {
+		return nil, fmt.Errorf("absolute path is not allowed: %s. Please use relative path", path)
 	}
 
 	// Build absolute path and check if file exists
 	absPath := filepath.Join(baseDir, path)
 	fi, err := os.Stat(absPath)
 	if os.IsNotExist(err) {
-		return fmt.Errorf("path does not exist: %s", absPath)
+		return nil, fmt.Errorf("path does not exist: %s", absPath)
 	}
 
 	if recursive && linkType == LinkTypeSymbolic {
-		return fmt.Errorf("recursive option cannot be used with symbolic links")
-	}
-
-	// Check existing links to avoid duplicates
-	existing := make(map[string]struct{})
-	for _, link := range config.Links {
-		existing[link.Path] = struct{}{}
+		return nil, fmt.Errorf("recursive option cannot be used with symbolic links")
 	}
 
 	var targets []string
 
 	// Add paths based on type and recursive flag
 	if fi.IsDir() {
-		if linkType == LinkTypeHard && !recursive {
-			return fmt.Errorf("recursive option must be set when adding a directory with hard links")
+		if (linkType == LinkTypeHard || linkType == LinkTypeEncrypted) && !recursive {
+			return nil, fmt.Errorf("recursive option must be set when adding a directory with %s links", linkType)
 		}
 
-		if linkType == LinkTypeHard {
-			// Walk directory and add all files for hard links
+		if linkType == LinkTypeHard || linkType == LinkTypeEncrypted || (linkType == LinkTypeCopy && recursive) {
+			// Walk directory and add all files for hard and encrypted links (or recursive copies)
 			err := filepath.Walk(absPath, func(p string, info os.FileInfo, err error) error {
 				if err != nil {
 					return err
 				}
+				// Skip paths matching the ignore patterns from the user configuration
+				if relPath, err := filepath.Rel(baseDir, p); err == nil && p != absPath && isIgnored(relPath, ignore) {
+					if info.IsDir() {
+						return filepath.SkipDir
+					}
+					return nil
+				}
 				if !info.IsDir() {
 					return addPathToTargets(p, baseDir, existing, &targets)
 				}
 				return nil
 			})
 			if err != nil {
-				return fmt.Errorf("failed to walk directory: %w", err)
+				return nil, fmt.Errorf("failed to walk directory: %w", err)
 			}
 		} else {
-			// Add directory itself for symbolic links
+			// Add directory itself for symbolic links and directory copies
 			if err := addPathToTargets(absPath, baseDir, existing, &targets); err != nil {
-				return err
+				return nil, err
 			}
 		}
 	} else {
 		// Add single file
 		if err := addPathToTargets(absPath, baseDir, existing, &targets); err != nil {
-			return err
+			return nil, err
 		}
 	}
 
-	if len(targets) == 0 {
-		fmt.Println("No new paths to add.")
-		return nil
+	return targets, nil
+}
+
+// addLinks records new links in .lnkr.toml and the ignore file, after making sure
+// that git does not track them
+func addLinks(config *Config, links []Link, opts AddOptions) error {
+	targets := make([]string, len(links))
+	for i, link := range links {
+		targets[i] = link.Path
+	}
+
+	// Excluding a path has no effect once git tracks it
+	if err := checkUntracked(config, targets, opts); err != nil {
+		return err
+	}
+
+	// Files that look like they hold secrets must end up excluded
+	var flagged []string
+	if opts.Scan {
+		baseDir, err := addBaseDir(config, opts.FromRemote)
+		if err != nil {
+			return err
+		}
+		if flagged, err = scanLinksForSecrets(links, baseDir); err != nil {
+			return err
+		}
+		if len(flagged) > 0 && config.GetIgnoreBackend() == IgnoreBackendNone && !opts.Force {
+			return fmt.Errorf("%d path(s) may contain secrets, but the ignore backend is none so they would not be excluded. Set ignore_backend, or use --force to add them anyway", len(flagged))
+		}
+		// Nothing is written unless the flagged paths will be excluded
+		if len(flagged) > 0 && config.GetIgnoreBackend() != IgnoreBackendNone {
+			if err := verifyExcluded(config, flagged); err != nil {
+				if !opts.Force {
+					return err
+				}
+				fmt.Printf("Warning: %v\n", err)
+			}
+		}
 	}
 
 	// Add links to config
-	for _, t := range targets {
-		config.Links = append(config.Links, Link{Path: t, Type: linkType})
-		fmt.Printf("Added link: %s (type: %s)\n", t, linkType)
+	for _, link := range links {
+		config.Links = append(config.Links, link)
+		fmt.Printf("Added link: %s (type: %s)\n", link.Path, link.Type)
 	}
 
 	sort.Slice(config.Links, func(i, j int) bool {
@@ -107,11 +234,7 @@ func Add(path string, recursive bool, linkType string, fromRemote bool) error {
 		return fmt.Errorf("failed to save configuration: %w", err)
 	}
 
-	// Add target paths to .git/info/exclude
-	if err := addTargetPathsToGitExclude(targets); err != nil {
-		fmt.Printf("Warning: failed to add target paths to .git/info/exclude: %v\n", err)
-	}
-
+	updateIgnoreFile(config)
 	return nil
 }
 
@@ -121,17 +244,70 @@ func addPathToTargets(absPath, baseDir string, existing map[string]struct{}, tar
 	if err != nil {
 		return fmt.Errorf("failed to get relative path: %w", err)
 	}
+	// Never manage the configuration file itself (it is mirrored into the remote)
+	// or the snapshots of the remote
+	if relPath == ConfigFileName || relPath == HistoryDirName || strings.HasPrefix(relPath, HistoryDirName+string(os.PathSeparator)) {
+		return nil
+	}
 	if _, ok := existing[relPath]; !ok {
 		*targets = append(*targets, relPath)
 	}
 	return nil
 }
 
-// addTargetPathsToGitExclude adds target paths of added links to .git/info/exclude
-func addTargetPathsToGitExclude(targetPaths []string) error {
-	// Git exclude always needs paths relative to the local directory (current directory)
-	// targetPaths are already relative paths from the base directory, so we can use them directly
+// checkUntracked refuses targets that are tracked by git, unless they are removed
+// from the index (Untrack) or added anyway (Force)
+func checkUntracked(config *Config, targets []string, opts AddOptions) error {
+	index, err := readGitIndex(config.Local)
+	if err != nil {
+		return fmt.Errorf("failed to read git index: %w", err)
+	}
+	// Projects outside a git repository have nothing tracked
+	if index == nil {
+		return nil
+	}
+
+	var tracked []string
+	for _, t := range targets {
+		if len(index.tracked(filepath.Join(config.Local, t))) > 0 {
+			tracked = append(tracked, t)
+		}
+	}
+	if len(tracked) == 0 {
+		return nil
+	}
 
-	// Add to .git/info/exclude
-	return addMultipleToGitExclude(targetPaths)
+	switch {
+	case opts.Untrack:
+		return untrackPaths(config.Local, tracked)
+	case opts.Force:
+		for _, t := range tracked {
+			fmt.Printf("Warning: %s is tracked by git; excluding it has no effect until it is removed from the index\n", t)
+		}
+		return nil
+	}
+
+	for _, t := range tracked {
+		fmt.Printf("Tracked: %s\n", t)
+	}
+	return fmt.Errorf("%d path(s) are tracked by git, so excluding them has no effect. Use --untrack to remove them from the index, or --force to add them anyway", len(tracked))
+}
+
+// untrackPaths removes paths from the git index, keeping the files on disk
+func untrackPaths(dir string, paths []string) error {
+	args := append([]string{"rm", "--cached", "-r", "-q", "--"}, paths...)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to remove paths from the git index: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	journalUntracked(dir, paths)
+	for _, p := range paths {
+		fmt.Printf("Removed from git index: %s\n", p)
+	}
+	fmt.Println("Commit the removal to stop tracking them (the files stay on disk)")
+	return nil
 }
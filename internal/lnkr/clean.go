@@ -3,19 +3,26 @@ package lnkr
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 )
 
 // Clean performs the cleanup tasks
 func Clean() error {
+	// Load config before removing it to know the git exclude path and the links
+	config, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
 	// Remove .lnkr.toml file if it exists
 	if err := removeLnkToml(); err != nil {
 		return fmt.Errorf("failed to remove %s: %w", ConfigFileName, err)
 	}
 
-	// Remove .lnkr.toml from .git/info/exclude
-	if err := removeFromGitExclude(); err != nil {
-		return fmt.Errorf("failed to remove from %s: %w", GitExcludePath, err)
+	// Remove .lnkr.toml from the ignore file, keeping the links that are still on disk
+	if err := removeFromIgnoreFile(config); err != nil {
+		return fmt.Errorf("failed to remove from %s: %w", config.GetIgnorePath(), err)
 	}
 
 	fmt.Println("Cleanup completed successfully!")
@@ -33,6 +40,7 @@ func removeLnkToml() error {
 	}
 
 	// Remove file
+	journalTouchConfig(filename)
 	if err := os.Remove(filename); err != nil {
 		return err
 	}
@@ -41,63 +49,215 @@ func removeLnkToml() error {
 	return nil
 }
 
-// removeFromGitExclude removes .lnkr.toml from .git/info/exclude
-func removeFromGitExclude() error {
-	// Load config to get git exclude path
-	config, err := loadConfig()
+// removeFromIgnoreFile removes .lnkr.toml from the LNKR section of the ignore file
+func removeFromIgnoreFile(config *Config) error {
+	writer, err := newIgnoreWriter(config)
 	if err != nil {
-		// If config doesn't exist, use default path
-		return removeFromGitExcludeWithPath(GitExcludePath, ConfigFileName)
+		return err
+	}
+	if writer.Path() == "" {
+		return nil
 	}
 
-	return removeFromGitExcludeWithPath(config.GetGitExcludePath(), ConfigFileName)
-}
+	current, _, err := writer.ReadSection()
+	if err != nil {
+		return err
+	}
 
-// removeFromGitExcludeWithPath removes entries from a specific git exclude file
-func removeFromGitExcludeWithPath(excludePath, entry string) error {
-	// Check if exclude file exists
-	if _, err := os.Stat(excludePath); os.IsNotExist(err) {
-		fmt.Printf("%s does not exist\n", excludePath)
+	var paths []string
+	found := false
+	for _, pattern := range current {
+		p := writer.ParsePattern(pattern)
+		if p == ConfigFileName {
+			found = true
+			continue
+		}
+		paths = append(paths, p)
+	}
+
+	if !found {
+		fmt.Printf("%s does not exist in %s\n", writer.Pattern(ConfigFileName), writer.Path())
 		return nil
 	}
 
-	// Read existing content
-	content, err := os.ReadFile(excludePath)
+	if err := writer.WriteSection(paths); err != nil {
+		return err
+	}
+
+	fmt.Printf("Removed %s from %s\n", writer.Pattern(ConfigFileName), writer.Path())
+	return nil
+}
+
+// CleanAllOptions controls the full teardown performed by CleanAll
+type CleanAllOptions struct {
+	// Restore replaces every link with a regular copy of the remote content instead of removing it
+	Restore bool
+	// PruneRemote removes empty directories left in the remote directory
+	PruneRemote bool
+	// Force tears down links that are not in sync with the remote
+	Force bool
+}
+
+// CleanAll fully tears down a project: it unlinks (or restores) every link, removes
+// the whole LNKR section from the ignore file and removes .lnkr.toml
+func CleanAll(opts CleanAllOptions) error {
+	config, err := loadExistingConfig()
 	if err != nil {
 		return err
 	}
+	if config.Remote == "" {
+		return fmt.Errorf("remote directory not configured. Run 'lnkr init --remote <path>' first")
+	}
 
-	// Split content into lines
-	lines := strings.Split(string(content), "\n")
+	absRemote, err := filepath.Abs(config.Remote)
+	if err != nil {
+		return fmt.Errorf("invalid remote directory path: %w", err)
+	}
 
-	// Check if entry exists
-	entryExists := false
-	for _, line := range lines {
-		if strings.TrimSpace(line) == entry {
-			entryExists = true
-			break
+	// Refuse to tear down links whose local side differs from the remote
+	drifted := make(map[string]error)
+	for _, link := range config.Links {
+		localAbs := filepath.Join(config.Local, link.Path)
+		if _, err := os.Lstat(localAbs); err != nil {
+			continue
+		}
+		if err := checkInSync(link, localAbs, filepath.Join(absRemote, link.Path)); err != nil {
+			drifted[link.Path] = err
 		}
 	}
-
-	if !entryExists {
-		fmt.Printf("%s does not exist in %s\n", entry, excludePath)
-		return nil
+	if len(drifted) > 0 && !opts.Force {
+		for _, link := range config.Links {
+			if err, ok := drifted[link.Path]; ok {
+				fmt.Printf("Drifted: %s (%v)\n", link.Path, err)
+			}
+		}
+		return fmt.Errorf("refusing to clean: %d link(s) are not in sync with the remote. Check them with 'lnkr diff' or use --force", len(drifted))
+	}
+	snapshotID, err := autoSnapshot(config, "before clean --all")
+	if err != nil {
+		return err
 	}
 
-	// Filter out the entry
-	var newLines []string
-	for _, line := range lines {
-		if strings.TrimSpace(line) != entry {
-			newLines = append(newLines, line)
+	unlinked, restored, kept, failed := 0, 0, 0, 0
+	for _, link := range config.Links {
+		localAbs := filepath.Join(config.Local, link.Path)
+		if _, err := os.Lstat(localAbs); os.IsNotExist(err) {
+			fmt.Printf("Path does not exist, skipping: %s\n", localAbs)
+			continue
+		}
+
+		if opts.Restore {
+			// Drifted links already hold their own content, and copies (including
+			// decrypted files) are already independent
+			if _, ok := drifted[link.Path]; ok || link.Type == LinkTypeCopy || link.Type == LinkTypeEncrypted {
+				fmt.Printf("Kept local file: %s\n", localAbs)
+				kept++
+				continue
+			}
+			journalTouchLink(link, localAbs, filepath.Join(absRemote, link.Path))
+			if err := replaceLocal(localAbs, filepath.Join(absRemote, link.Path), LinkTypeCopy); err != nil {
+				fmt.Printf("Error restoring %s: %v\n", link.Path, err)
+				failed++
+				continue
+			}
+			fmt.Printf("Restored %s as a regular copy\n", localAbs)
+			restored++
+			continue
 		}
+
+		journalTouchLink(link, localAbs, filepath.Join(absRemote, link.Path))
+		if err := removeLinkWithBase(link, config.Local); err != nil {
+			fmt.Printf("Error removing link for %s: %v\n", link.Path, err)
+			failed++
+			continue
+		}
+		unlinked++
+		removeEmptyParents(filepath.Dir(localAbs), config.Local)
 	}
 
-	// Write back the filtered content
-	newContent := strings.Join(newLines, "\n")
-	if err := os.WriteFile(excludePath, []byte(newContent), 0644); err != nil {
+	if failed > 0 {
+		return fmt.Errorf("failed to tear down %d link(s); %s and the ignore file section were kept", failed, ConfigFileName)
+	}
+
+	// Remove the whole LNKR section including its markers
+	writer, err := newIgnoreWriter(config)
+	if err != nil {
 		return err
 	}
+	if writer.Path() != "" {
+		if err := writer.WriteSection(nil); err != nil {
+			return fmt.Errorf("failed to remove LNKR section from %s: %w", writer.Path(), err)
+		}
+		fmt.Printf("Removed LNKR section from %s\n", writer.Path())
+	}
+
+	if err := removeLnkToml(); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", ConfigFileName, err)
+	}
+
+	if opts.PruneRemote {
+		pruned, err := pruneEmptyDirs(absRemote)
+		if err != nil {
+			fmt.Printf("Warning: failed to prune remote directories: %v\n", err)
+		}
+		fmt.Printf("Removed %d empty remote directories\n", pruned)
+	}
 
-	fmt.Printf("Removed %s from %s\n", entry, excludePath)
+	fmt.Printf("Cleanup completed successfully! (unlinked: %d, restored: %d, kept: %d)\n", unlinked, restored, kept)
+	if snapshotID != "" {
+		fmt.Printf("To bring the links back, run 'lnkr init --from-remote' and 'lnkr snapshot restore %s'\n", snapshotID)
+	}
 	return nil
 }
+
+// removeEmptyParents removes dir and its parents while they are empty, stopping at root
+func removeEmptyParents(dir, root string) {
+	root = filepath.Clean(root)
+	for dir = filepath.Clean(dir); dir != root && strings.HasPrefix(dir, root+string(os.PathSeparator)); dir = filepath.Dir(dir) {
+		if err := removeEmptyDir(dir); err != nil {
+			return
+		}
+		fmt.Printf("Removed empty directory: %s\n", dir)
+	}
+}
+
+// pruneEmptyDirs removes every empty directory under root (including root itself)
+// and returns how many were removed
+func pruneEmptyDirs(root string) (int, error) {
+	var dirs []string
+	err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			dirs = append(dirs, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// Deepest directories first, so parents can become empty
+	pruned := 0
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if err := removeEmptyDir(dirs[i]); err == nil {
+			fmt.Printf("Removed empty directory: %s\n", dirs[i])
+			pruned++
+		}
+	}
+	return pruned, nil
+}
+
+// removeEmptyDir removes dir if it is empty
+func removeEmptyDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	if len(entries) > 0 {
+		return fmt.Errorf("directory not empty: %s", dir)
+	}
+	journalTouch(dir)
+	return os.Remove(dir)
+}
@@ -0,0 +1,131 @@
+package lnkr
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// CompleteLinkPaths returns the configured link paths starting with prefix,
+// leaving out the paths in exclude
+func CompleteLinkPaths(prefix string, exclude []string) []string {
+	config, err := loadConfig()
+	if err != nil {
+		return nil
+	}
+
+	var paths []string
+	for _, link := range config.Links {
+		if strings.HasPrefix(link.Path, prefix) && !slices.Contains(exclude, link.Path) {
+			paths = append(paths, link.Path)
+		}
+	}
+	return paths
+}
+
+// CompleteRemotePaths returns the entries of the remote directory matching prefix,
+// one directory level at a time. Directories end with a separator, and paths that
+// are already links are left out.
+func CompleteRemotePaths(prefix string) []string {
+	config, err := loadConfig()
+	if err != nil || config.Remote == "" {
+		return nil
+	}
+
+	dir, base := filepath.Split(prefix)
+	entries, err := os.ReadDir(filepath.Join(config.Remote, dir))
+	if err != nil {
+		return nil
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, base) {
+			continue
+		}
+		// The remote keeps a copy of .lnkr.toml and the snapshots, which are not link candidates
+		if dir == "" && (name == ConfigFileName || name == HistoryDirName) {
+			continue
+		}
+
+		path := dir + name
+		if slices.ContainsFunc(config.Links, func(link Link) bool { return link.Path == filepath.Clean(path) }) {
+			continue
+		}
+		if entry.IsDir() {
+			path += string(os.PathSeparator)
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// CompleteConfigKeys returns the setting keys starting with prefix, including the
+// per-link keys of the configured links
+func CompleteConfigKeys(prefix string) []string {
+	var keys []string
+	for _, name := range configSettingNames {
+		if strings.HasPrefix(name, prefix) {
+			keys = append(keys, name)
+		}
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return keys
+	}
+	for _, link := range config.Links {
+		for _, name := range linkSettingNames {
+			if key := linkKeyPrefix + link.Path + "." + name; strings.HasPrefix(key, prefix) {
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+// CompleteConfigValues returns the values accepted by a setting with a fixed set
+// of values, or nil for free-form settings
+func CompleteConfigValues(key string) []string {
+	if _, name, ok := parseLinkKey(key); ok {
+		switch name {
+		case "type":
+			return LinkTypes
+		case "template":
+			return []string{"true", "false"}
+		}
+		return nil
+	}
+	if key == "ignore_backend" {
+		return IgnoreBackends
+	}
+	return nil
+}
+
+// CompleteSnapshotIDs returns the snapshot IDs starting with prefix, newest first,
+// described by the reason of the snapshot
+func CompleteSnapshotIDs(prefix string) []string {
+	config, err := loadConfig()
+	if err != nil || config.Remote == "" {
+		return nil
+	}
+	manifests, err := loadSnapshots(config)
+	if err != nil {
+		return nil
+	}
+
+	var ids []string
+	for _, m := range slices.Backward(manifests) {
+		if !strings.HasPrefix(m.ID, prefix) {
+			continue
+		}
+		if m.Reason != "" {
+			ids = append(ids, m.ID+"\t"+m.Reason)
+		} else {
+			ids = append(ids, m.ID)
+		}
+	}
+	return ids
+}
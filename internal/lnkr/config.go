@@ -1,8 +1,10 @@
 package lnkr
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/BurntSushi/toml"
@@ -22,22 +24,35 @@ const (
 
 // Link type constants
 const (
-	LinkTypeHard     = "hard"
-	LinkTypeSymbolic = "symbolic"
+	LinkTypeHard      = "hard"
+	LinkTypeSymbolic  = "symbolic"
+	LinkTypeCopy      = "copy"
+	LinkTypeEncrypted = "encrypted"
 )
 
+// LinkTypes lists the supported link types
+var LinkTypes = []string{LinkTypeHard, LinkTypeSymbolic, LinkTypeCopy, LinkTypeEncrypted}
+
 // Default remote depth constant
 const DefaultRemoteDepth = 2
 
 type Link struct {
 	Path string `toml:"path"`
 	Type string `toml:"type"`
+	// Permission mode enforced on the local path, in octal (e.g. 0600)
+	Mode string `toml:"mode,omitempty"`
+	// Owner enforced on the local path, as user or user:group
+	Owner string `toml:"owner,omitempty"`
+	// Render the remote file as a Go template into the local path (copy links only)
+	Template bool `toml:"template,omitempty"`
 }
 
 type Config struct {
+	Version        int    `toml:"version"`
 	Local          string `toml:"local"`
 	Remote         string `toml:"remote"`
 	GitExcludePath string `toml:"git_exclude_path"`
+	IgnoreBackend  string `toml:"ignore_backend,omitempty"`
 	Links          []Link `toml:"links"`
 }
 
@@ -75,7 +90,7 @@ func GetDefaultRemotePath(baseDir, remoteDir string, depth int) string {
 
 func loadConfig() (*Config, error) {
 	filename := ConfigFileName
-	config := &Config{}
+	config := &Config{Version: ConfigVersion}
 
 	if _, err := os.Stat(filename); os.IsNotExist(err) {
 		return config, nil
@@ -86,18 +101,121 @@ func loadConfig() (*Config, error) {
 		return nil, err
 	}
 
-	if len(content) > 0 {
-		if _, err := toml.Decode(string(content), config); err != nil {
-			return nil, err
-		}
+	if len(content) == 0 {
+		return config, nil
+	}
+
+	config, fromVersion, err := decodeConfig(content)
+	if err != nil {
+		return nil, err
+	}
+	if fromVersion < ConfigVersion {
+		// Printed to stderr so machine-readable output (e.g. 'lnkr list --format json') stays clean
+		fmt.Fprintf(os.Stderr, "Warning: %s uses schema version %d (current: %d). Run 'lnkr config migrate' to upgrade it\n", filename, fromVersion, ConfigVersion)
 	}
 
 	return config, nil
 }
 
+// decodeConfig strictly decodes the content of a configuration file, upgrades it
+// to the current schema version in memory and validates it.
+// It returns the decoded configuration and the schema version it was written with.
+func decodeConfig(content []byte) (*Config, int, error) {
+	config, fromVersion, err := parseConfig(content)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := validateLinks(config); err != nil {
+		return nil, 0, err
+	}
+
+	if config.IgnoreBackend != "" && !isValidIgnoreBackend(config.IgnoreBackend) {
+		return nil, 0, fmt.Errorf("invalid ignore backend %q. Must be one of: %s", config.IgnoreBackend, strings.Join(IgnoreBackends, ", "))
+	}
+
+	return config, fromVersion, nil
+}
+
+// parseConfig strictly decodes the content of a configuration file and upgrades it
+// to the current schema version without validating the values
+func parseConfig(content []byte) (*Config, int, error) {
+	config := &Config{}
+
+	md, err := toml.Decode(string(content), config)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		keys := make([]string, len(undecoded))
+		for i, key := range undecoded {
+			keys[i] = key.String()
+		}
+		return nil, 0, fmt.Errorf("unknown key(s): %s", strings.Join(keys, ", "))
+	}
+
+	fromVersion := config.Version
+	if err := migrateConfig(config); err != nil {
+		return nil, 0, err
+	}
+
+	return config, fromVersion, nil
+}
+
+// validateLinks checks that every link has a known type and valid options
+func validateLinks(config *Config) error {
+	for _, link := range config.Links {
+		if !isValidLinkType(link.Type) {
+			return fmt.Errorf("invalid link type %q for %s. Must be one of: %s", link.Type, link.Path, strings.Join(LinkTypes, ", "))
+		}
+		if err := validateLinkOptions(link); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isValidLinkType reports whether linkType is a known link type
+func isValidLinkType(linkType string) bool {
+	return slices.Contains(LinkTypes, linkType)
+}
+
+// selectLinks returns the links at or under the given paths, or all links when no
+// path is given
+func selectLinks(config *Config, paths []string) ([]Link, error) {
+	if len(paths) == 0 {
+		return config.Links, nil
+	}
+
+	var links []Link
+	for _, p := range paths {
+		if filepath.IsAbs(p) {
+			return nil, fmt.Errorf("absolute path is not allowed: %s. Please use relative path", p)
+		}
+		p = filepath.Clean(p)
+
+		found := false
+		for _, link := range config.Links {
+			if p != "." && link.Path != p && !strings.HasPrefix(link.Path, p+string(os.PathSeparator)) {
+				continue
+			}
+			found = true
+			if !slices.ContainsFunc(links, func(l Link) bool { return l.Path == link.Path }) {
+				links = append(links, link)
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("link not found: %s", p)
+		}
+	}
+	return links, nil
+}
+
 func saveConfig(config *Config) error {
 	filename := ConfigFileName
 
+	journalTouchConfig(filename)
 	file, err := os.Create(filename)
 	if err != nil {
 		return err
@@ -109,13 +227,52 @@ func saveConfig(config *Config) error {
 		return err
 	}
 
+	// Keep a copy in the remote so a fresh clone can be bootstrapped from it
+	if err := mirrorConfigToRemote(config.Remote); err != nil {
+		fmt.Printf("Warning: failed to mirror %s to remote: %v\n", ConfigFileName, err)
+	}
+
 	return nil
 }
 
-// GetGitExcludePath returns the git exclude path from config or default value
+// mirrorConfigToRemote copies the local .lnkr.toml into the remote directory
+func mirrorConfigToRemote(remote string) error {
+	if remote == "" {
+		return nil
+	}
+
+	// Only mirror into an existing remote directory
+	info, err := os.Stat(remote)
+	if err != nil || !info.IsDir() {
+		return nil
+	}
+
+	content, err := os.ReadFile(ConfigFileName)
+	if err != nil {
+		return err
+	}
+
+	journalTouchConfig(filepath.Join(remote, ConfigFileName))
+	return os.WriteFile(filepath.Join(remote, ConfigFileName), content, 0644)
+}
+
+// GetGitExcludePath returns the git exclude path from config or default value.
+// The default (.git/info/exclude) is resolved through .git files and commondir,
+// so it points at the right file in worktrees and submodules.
 func (c *Config) GetGitExcludePath() string {
-	if c.GitExcludePath != "" {
+	if c.GitExcludePath != "" && filepath.Clean(c.GitExcludePath) != GitExcludePath {
 		return c.GitExcludePath
 	}
-	return GitExcludePath
+
+	baseDir := c.Local
+	if baseDir == "" {
+		baseDir = "."
+	}
+	excludePath, err := gitPath(baseDir, "info/exclude")
+	if err != nil {
+		return GitExcludePath
+	}
+
+	// Keep the familiar relative form when the file is inside the current directory
+	return displayPath(excludePath)
 }
@@ -0,0 +1,322 @@
+package lnkr
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Convert changes the type of a configured link and transforms the link on disk.
+// Directory entries are expanded into per-file hard links, or collapsed from
+// per-file entries into a single symbolic link or copy, as needed.
+func Convert(path string, to string) error {
+	if !isValidLinkType(to) {
+		return fmt.Errorf("invalid link type: %s. Must be one of: %s", to, strings.Join(LinkTypes, ", "))
+	}
+
+	if filepath.IsAbs(path) {
+		return fmt.Errorf("absolute path is not allowed: %s. Please use relative path", path)
+	}
+	path = filepath.Clean(path)
+
+	config, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if config.Local == "" {
+		return fmt.Errorf("local directory not configured. Run 'lnkr init' first")
+	}
+	if config.Remote == "" {
+		return fmt.Errorf("remote directory not configured. Run 'lnkr init --remote <path>' first")
+	}
+
+	absRemote, err := filepath.Abs(config.Remote)
+	if err != nil {
+		return fmt.Errorf("invalid remote directory path: %w", err)
+	}
+	localAbs := filepath.Join(config.Local, path)
+	remoteAbs := filepath.Join(absRemote, path)
+
+	// Collect the entries affected by the conversion
+	var matched []Link
+	var kept []Link
+	for _, link := range config.Links {
+		if link.Path == path || strings.HasPrefix(link.Path, path+string(os.PathSeparator)) {
+			matched = append(matched, link)
+			continue
+		}
+		kept = append(kept, link)
+	}
+	if len(matched) == 0 {
+		return fmt.Errorf("link not found: %s", path)
+	}
+	for _, link := range matched {
+		// The remote of an encrypted link holds ciphertext, which no other type can use
+		if link.Type == LinkTypeEncrypted || to == LinkTypeEncrypted {
+			return fmt.Errorf("%s cannot be converted to or from an %s link. Remove it and add it again with the new type", link.Path, LinkTypeEncrypted)
+		}
+		if link.Template {
+			return fmt.Errorf("%s is rendered from a template and must stay a %s link. Run 'lnkr config set link.%s.template false' first", link.Path, LinkTypeCopy, link.Path)
+		}
+	}
+	if len(matched) == 1 && matched[0].Path == path && matched[0].Type == to {
+		fmt.Printf("%s is already a %s link\n", path, to)
+		return nil
+	}
+
+	// The remote holds the data, so it must exist before anything is touched
+	remoteInfo, err := os.Stat(remoteAbs)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("remote path does not exist: %s. Run 'lnkr link' first", remoteAbs)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to stat remote path: %w", err)
+	}
+
+	// Work out the new entries
+	var converted []Link
+	if remoteInfo.IsDir() && to == LinkTypeHard {
+		// Hard links require one entry per file
+		files, err := listFiles(remoteAbs)
+		if err != nil {
+			return fmt.Errorf("failed to walk directory: %w", err)
+		}
+		for _, f := range files {
+			converted = append(converted, Link{Path: filepath.Join(path, f), Type: LinkTypeHard})
+		}
+	} else {
+		converted = []Link{{Path: path, Type: to}}
+		// A single entry keeps its mode and owner
+		if len(matched) == 1 && matched[0].Path == path {
+			converted[0].Mode = matched[0].Mode
+			converted[0].Owner = matched[0].Owner
+		}
+	}
+
+	// Make sure nothing on the local side would be lost by the conversion
+	if _, err := os.Lstat(localAbs); err == nil {
+		if err := checkConvertible(matched, config.Local, absRemote, localAbs); err != nil {
+			return err
+		}
+		if _, err := autoSnapshot(config, "before convert of "+path); err != nil {
+			return err
+		}
+		journalTouch(localAbs)
+		for _, link := range matched {
+			journalTouchLink(link, filepath.Join(config.Local, link.Path), filepath.Join(absRemote, link.Path))
+		}
+		if err := replaceLocal(localAbs, remoteAbs, to); err != nil {
+			return err
+		}
+		fmt.Printf("Converted %s to %s link on disk\n", localAbs, to)
+	} else if os.IsNotExist(err) {
+		fmt.Printf("%s is not linked on disk. Run 'lnkr link --from-remote' to create it\n", localAbs)
+	} else {
+		return fmt.Errorf("failed to stat local path: %w", err)
+	}
+
+	config.Links = append(kept, converted...)
+	sort.Slice(config.Links, func(i, j int) bool {
+		return config.Links[i].Path < config.Links[j].Path
+	})
+
+	if err := saveConfig(config); err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+
+	for _, link := range matched {
+		fmt.Printf("Removed link: %s (type: %s)\n", link.Path, link.Type)
+	}
+	for _, link := range converted {
+		fmt.Printf("Added link: %s (type: %s)\n", link.Path, link.Type)
+	}
+
+	updateIgnoreFile(config)
+
+	return nil
+}
+
+// checkConvertible verifies that every matched entry is in sync with the remote
+// and that the local path holds nothing but managed entries
+func checkConvertible(matched []Link, localDir, remoteDir, localAbs string) error {
+	managed := make(map[string]struct{})
+	for _, link := range matched {
+		linkLocal := filepath.Join(localDir, link.Path)
+		linkRemote := filepath.Join(remoteDir, link.Path)
+		managed[linkLocal] = struct{}{}
+
+		if _, err := os.Lstat(linkLocal); os.IsNotExist(err) {
+			continue
+		}
+		if err := checkInSync(link, linkLocal, linkRemote); err != nil {
+			return fmt.Errorf("%s: %w. Resolve the difference before converting", link.Path, err)
+		}
+	}
+
+	// A local directory made of per-file entries must not contain unmanaged files
+	info, err := os.Lstat(localAbs)
+	if err != nil || !info.IsDir() {
+		return nil
+	}
+	if _, ok := managed[localAbs]; ok {
+		return nil
+	}
+	files, err := listFiles(localAbs)
+	if err != nil {
+		return fmt.Errorf("failed to walk directory: %w", err)
+	}
+	for _, f := range files {
+		if _, ok := managed[filepath.Join(localAbs, f)]; !ok {
+			return fmt.Errorf("local directory contains unmanaged file: %s", filepath.Join(localAbs, f))
+		}
+	}
+	return nil
+}
+
+// checkInSync returns an error if the local side of a link differs from the remote
+func checkInSync(link Link, localAbs, remoteAbs string) error {
+	localInfo, err := os.Lstat(localAbs)
+	if err != nil {
+		return err
+	}
+
+	switch link.Type {
+	case LinkTypeHard:
+		remoteInfo, err := os.Stat(remoteAbs)
+		if err != nil {
+			return fmt.Errorf("cannot access remote: %w", err)
+		}
+		if !os.SameFile(localInfo, remoteInfo) {
+			return fmt.Errorf("not a hard link (different inodes)")
+		}
+	case LinkTypeSymbolic:
+		if localInfo.Mode()&os.ModeSymlink == 0 {
+			return fmt.Errorf("not a symbolic link")
+		}
+		target, err := os.Readlink(localAbs)
+		if err != nil {
+			return fmt.Errorf("cannot read link target: %w", err)
+		}
+		if target != remoteAbs {
+			return fmt.Errorf("wrong target: %s (expected: %s)", target, remoteAbs)
+		}
+	case LinkTypeCopy, LinkTypeEncrypted:
+		same, err := sameAsRemote(link, localAbs, remoteAbs)
+		if err != nil {
+			return fmt.Errorf("cannot compare content: %w", err)
+		}
+		if !same && link.Type == LinkTypeEncrypted {
+			return fmt.Errorf("local changes are not encrypted")
+		}
+		if !same && link.Template {
+			return fmt.Errorf("content differs from rendered template")
+		}
+		if !same {
+			return fmt.Errorf("content differs from remote")
+		}
+	}
+	return nil
+}
+
+// replaceLocal builds the new link next to the local path and swaps it in.
+// Files and symbolic links are replaced atomically with a rename; directories
+// are moved aside first and restored if the swap fails.
+func replaceLocal(localAbs, remoteAbs, to string) error {
+	tmp := filepath.Join(filepath.Dir(localAbs), fmt.Sprintf(".%s.lnkr-convert", filepath.Base(localAbs)))
+	if err := os.RemoveAll(tmp); err != nil {
+		return fmt.Errorf("failed to clean up temporary path: %w", err)
+	}
+
+	if err := buildLink(remoteAbs, tmp, to); err != nil {
+		os.RemoveAll(tmp)
+		return err
+	}
+
+	localInfo, err := os.Lstat(localAbs)
+	if err != nil {
+		os.RemoveAll(tmp)
+		return err
+	}
+	tmpInfo, err := os.Lstat(tmp)
+	if err != nil {
+		os.RemoveAll(tmp)
+		return err
+	}
+
+	// rename(2) atomically replaces anything but a directory
+	if !localInfo.IsDir() && !tmpInfo.IsDir() {
+		if err := os.Rename(tmp, localAbs); err != nil {
+			os.RemoveAll(tmp)
+			return fmt.Errorf("failed to replace %s: %w", localAbs, err)
+		}
+		return nil
+	}
+
+	backup := filepath.Join(filepath.Dir(localAbs), fmt.Sprintf(".%s.lnkr-backup", filepath.Base(localAbs)))
+	if err := os.RemoveAll(backup); err != nil {
+		os.RemoveAll(tmp)
+		return fmt.Errorf("failed to clean up backup path: %w", err)
+	}
+	if err := os.Rename(localAbs, backup); err != nil {
+		os.RemoveAll(tmp)
+		return fmt.Errorf("failed to move %s aside: %w", localAbs, err)
+	}
+	if err := os.Rename(tmp, localAbs); err != nil {
+		os.Rename(backup, localAbs)
+		os.RemoveAll(tmp)
+		return fmt.Errorf("failed to replace %s: %w", localAbs, err)
+	}
+
+	// The backup only holds links to, or copies identical to, the remote
+	if err := os.RemoveAll(backup); err != nil {
+		fmt.Printf("Warning: failed to remove backup %s: %v\n", backup, err)
+	}
+	return nil
+}
+
+// buildLink creates a link of the given type at target for the remote path
+func buildLink(remoteAbs, target, linkType string) error {
+	switch linkType {
+	case LinkTypeSymbolic:
+		if err := os.Symlink(remoteAbs, target); err != nil {
+			return fmt.Errorf("failed to create symbolic link: %w", err)
+		}
+	case LinkTypeCopy:
+		if err := copyPath(remoteAbs, target); err != nil {
+			return fmt.Errorf("failed to create copy: %w", err)
+		}
+	case LinkTypeHard:
+		info, err := os.Stat(remoteAbs)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			if err := os.Link(remoteAbs, target); err != nil {
+				return fmt.Errorf("failed to create hard link: %w", err)
+			}
+			return nil
+		}
+		// Rebuild the directory tree with a hard link per file
+		err = filepath.Walk(remoteAbs, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(remoteAbs, p)
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return os.MkdirAll(filepath.Join(target, rel), info.Mode().Perm())
+			}
+			return os.Link(p, filepath.Join(target, rel))
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create hard links: %w", err)
+		}
+	default:
+		return fmt.Errorf("unknown link type: %s", linkType)
+	}
+	return nil
+}
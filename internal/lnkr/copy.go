@@ -0,0 +1,156 @@
+package lnkr
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// copyFile copies a regular file, preserving its permission bits
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// writeFileAtomic writes a file next to its destination and renames it into
+// place, so that readers never see a partial file
+func writeFileAtomic(path string, content []byte, mode os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".lnkr-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// copyPath copies a file or a directory tree from src to dst
+func copyPath(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		return copyFile(src, dst)
+	}
+
+	return filepath.Walk(src, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if info.IsDir() {
+			return os.MkdirAll(target, info.Mode().Perm())
+		}
+		return copyFile(p, target)
+	})
+}
+
+// sameContent reports whether two files, or two directory trees, have identical content
+func sameContent(a, b string) (bool, error) {
+	infoA, err := os.Stat(a)
+	if err != nil {
+		return false, err
+	}
+	infoB, err := os.Stat(b)
+	if err != nil {
+		return false, err
+	}
+
+	if infoA.IsDir() != infoB.IsDir() {
+		return false, nil
+	}
+
+	if !infoA.IsDir() {
+		if infoA.Size() != infoB.Size() {
+			return false, nil
+		}
+		contentA, err := os.ReadFile(a)
+		if err != nil {
+			return false, err
+		}
+		contentB, err := os.ReadFile(b)
+		if err != nil {
+			return false, err
+		}
+		return bytes.Equal(contentA, contentB), nil
+	}
+
+	filesA, err := listFiles(a)
+	if err != nil {
+		return false, err
+	}
+	filesB, err := listFiles(b)
+	if err != nil {
+		return false, err
+	}
+	if len(filesA) != len(filesB) {
+		return false, nil
+	}
+	for i := range filesA {
+		if filesA[i] != filesB[i] {
+			return false, nil
+		}
+		same, err := sameContent(filepath.Join(a, filesA[i]), filepath.Join(b, filesB[i]))
+		if err != nil || !same {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// listFiles returns the paths of all files under dir, relative to dir, in lexical order
+func listFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return fmt.Errorf("failed to get relative path: %w", err)
+		}
+		files = append(files, rel)
+		return nil
+	})
+	return files, err
+}
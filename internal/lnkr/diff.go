@@ -0,0 +1,508 @@
+package lnkr
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Number of context lines around each hunk of a unified diff
+const diffContextLines = 3
+
+// Number of edits the search for the middle of an edit script may try before the
+// rest of the block is shown as replaced, which keeps very different files fast
+const diffMaxCost = 1024
+
+// Number of leading bytes inspected when detecting binary files
+const binaryDetectionSize = 8000
+
+// Diff prints the differences between the local and remote copies of links.
+// Without paths, only links that are not in sync are compared.
+func Diff(paths []string) error {
+	config, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if config.Local == "" {
+		return fmt.Errorf("local directory not configured. Run 'lnkr init' first")
+	}
+	if config.Remote == "" {
+		return fmt.Errorf("remote directory not configured. Run 'lnkr init --remote <path>' first")
+	}
+
+	absRemote, err := filepath.Abs(config.Remote)
+	if err != nil {
+		return fmt.Errorf("invalid remote directory path: %w", err)
+	}
+
+	targets, err := selectDiffTargets(config, paths)
+	if err != nil {
+		return err
+	}
+
+	differences := 0
+	for _, target := range targets {
+		localAbs := filepath.Join(config.Local, target.path)
+		remoteAbs := filepath.Join(absRemote, target.path)
+
+		// Links in sync cannot differ, so only drifted links are compared by default
+		if len(paths) == 0 {
+			if _, err := os.Lstat(localAbs); err == nil {
+				if checkInSync(target.link, localAbs, remoteAbs) == nil {
+					continue
+				}
+			}
+		}
+
+		var differs bool
+		if target.link.Template || target.link.Type == LinkTypeEncrypted {
+			differs, err = diffGenerated(target.link, localAbs, remoteAbs)
+		} else {
+			differs, err = diffPaths(localAbs, remoteAbs)
+		}
+		if err != nil {
+			fmt.Printf("Error comparing %s: %v\n", target.path, err)
+			continue
+		}
+		if differs {
+			differences++
+		}
+	}
+
+	if differences == 0 {
+		fmt.Println("No differences found.")
+	}
+	return nil
+}
+
+// diffTarget is a path to compare, together with the link it belongs to
+type diffTarget struct {
+	path string
+	link Link
+}
+
+// selectDiffTargets resolves the requested paths to links. A path may name a link,
+// a directory containing links, or a file inside a symbolic directory link.
+func selectDiffTargets(config *Config, paths []string) ([]diffTarget, error) {
+	if len(paths) == 0 {
+		targets := make([]diffTarget, len(config.Links))
+		for i, link := range config.Links {
+			targets[i] = diffTarget{path: link.Path, link: link}
+		}
+		return targets, nil
+	}
+
+	var targets []diffTarget
+	for _, p := range paths {
+		if filepath.IsAbs(p) {
+			return nil, fmt.Errorf("absolute path is not allowed: %s. Please use relative path", p)
+		}
+		p = filepath.Clean(p)
+
+		found := false
+		for _, link := range config.Links {
+			switch {
+			case link.Path == p || strings.HasPrefix(link.Path, p+string(os.PathSeparator)):
+				targets = append(targets, diffTarget{path: link.Path, link: link})
+				found = true
+			case strings.HasPrefix(p, link.Path+string(os.PathSeparator)):
+				targets = append(targets, diffTarget{path: p, link: link})
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("link not found: %s", p)
+		}
+	}
+	return targets, nil
+}
+
+// diffPaths prints the differences between a local and a remote path and
+// reports whether they differ
+func diffPaths(localAbs, remoteAbs string) (bool, error) {
+	localInfo, localErr := os.Stat(localAbs)
+	remoteInfo, remoteErr := os.Stat(remoteAbs)
+
+	switch {
+	case os.IsNotExist(localErr) && os.IsNotExist(remoteErr):
+		fmt.Printf("Missing on both sides: %s\n", localAbs)
+		return true, nil
+	case os.IsNotExist(localErr):
+		fmt.Printf("Only in remote: %s\n", remoteAbs)
+		return true, nil
+	case os.IsNotExist(remoteErr):
+		fmt.Printf("Only in local: %s\n", localAbs)
+		return true, nil
+	case localErr != nil:
+		return false, localErr
+	case remoteErr != nil:
+		return false, remoteErr
+	}
+
+	// Hard links and symbolic links resolving to the remote are identical
+	if os.SameFile(localInfo, remoteInfo) {
+		return false, nil
+	}
+
+	if localInfo.IsDir() != remoteInfo.IsDir() {
+		if localInfo.IsDir() {
+			fmt.Printf("%s is a directory while %s is a file\n", localAbs, remoteAbs)
+		} else {
+			fmt.Printf("%s is a file while %s is a directory\n", localAbs, remoteAbs)
+		}
+		return true, nil
+	}
+
+	if localInfo.IsDir() {
+		return diffDirs(localAbs, remoteAbs)
+	}
+	return diffFiles(localAbs, remoteAbs)
+}
+
+// diffDirs prints a summary of the differences between two directory trees
+func diffDirs(localAbs, remoteAbs string) (bool, error) {
+	localFiles, err := listFiles(localAbs)
+	if err != nil {
+		return false, err
+	}
+	remoteFiles, err := listFiles(remoteAbs)
+	if err != nil {
+		return false, err
+	}
+
+	remoteSet := make(map[string]struct{}, len(remoteFiles))
+	for _, f := range remoteFiles {
+		remoteSet[f] = struct{}{}
+	}
+	localSet := make(map[string]struct{}, len(localFiles))
+	for _, f := range localFiles {
+		localSet[f] = struct{}{}
+	}
+
+	differs := false
+	for _, f := range localFiles {
+		if _, ok := remoteSet[f]; !ok {
+			fmt.Printf("Only in local: %s\n", filepath.Join(localAbs, f))
+			differs = true
+			continue
+		}
+		same, err := sameContent(filepath.Join(localAbs, f), filepath.Join(remoteAbs, f))
+		if err != nil {
+			return differs, err
+		}
+		if !same {
+			fmt.Printf("Files differ: %s and %s\n", filepath.Join(localAbs, f), filepath.Join(remoteAbs, f))
+			differs = true
+		}
+	}
+	for _, f := range remoteFiles {
+		if _, ok := localSet[f]; !ok {
+			fmt.Printf("Only in remote: %s\n", filepath.Join(remoteAbs, f))
+			differs = true
+		}
+	}
+	return differs, nil
+}
+
+// diffFiles prints a unified diff between two files, or a notice for binary files
+func diffFiles(localAbs, remoteAbs string) (bool, error) {
+	localContent, err := os.ReadFile(localAbs)
+	if err != nil {
+		return false, err
+	}
+	remoteContent, err := os.ReadFile(remoteAbs)
+	if err != nil {
+		return false, err
+	}
+
+	return diffContent(localAbs, remoteAbs, localContent, remoteContent), nil
+}
+
+// diffGenerated prints a unified diff between the local path of a template or
+// encrypted link and the rendered or decrypted remote
+func diffGenerated(link Link, localAbs, remoteAbs string) (bool, error) {
+	label := " (rendered)"
+	if link.Type == LinkTypeEncrypted {
+		label = " (decrypted)"
+	}
+
+	if _, err := os.Stat(localAbs); os.IsNotExist(err) {
+		fmt.Printf("Only in remote: %s\n", remoteAbs)
+		return true, nil
+	}
+	if _, err := os.Stat(remoteAbs); os.IsNotExist(err) {
+		fmt.Printf("Only in local: %s\n", localAbs)
+		return true, nil
+	}
+	localContent, err := os.ReadFile(localAbs)
+	if err != nil {
+		return false, err
+	}
+	expected, err := expectedLocalContent(link, localAbs, remoteAbs)
+	if err != nil {
+		return false, err
+	}
+
+	return diffContent(localAbs, remoteAbs+label, localContent, expected), nil
+}
+
+// diffContent prints a unified diff between two contents, or a notice for binary
+// content, and reports whether they differ
+func diffContent(localName, remoteName string, localContent, remoteContent []byte) bool {
+	if bytes.Equal(localContent, remoteContent) {
+		return false
+	}
+
+	if isBinary(localContent) || isBinary(remoteContent) {
+		fmt.Printf("Binary files %s and %s differ\n", localName, remoteName)
+		return true
+	}
+
+	fmt.Print(unifiedDiff(localName, remoteName, string(localContent), string(remoteContent)))
+	return true
+}
+
+// isBinary reports whether content looks binary (contains a NUL byte near the start)
+func isBinary(content []byte) bool {
+	if len(content) > binaryDetectionSize {
+		content = content[:binaryDetectionSize]
+	}
+	return bytes.IndexByte(content, 0) != -1
+}
+
+// diffOp is a single line of an edit script: ' ' (equal), '-' (delete) or '+' (insert)
+type diffOp struct {
+	kind byte
+	line string
+	// Index of the line in the old and new text at the point of this operation
+	a, b int
+}
+
+// unifiedDiff returns the unified diff between two texts
+func unifiedDiff(nameA, nameB, textA, textB string) string {
+	ops := diffLines(strings.SplitAfter(textA, "\n"), strings.SplitAfter(textB, "\n"))
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", nameA, nameB)
+
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+
+		// Extend the hunk while changes are closer than twice the context
+		start := max(0, i-diffContextLines)
+		end := i
+		for j := i; j < len(ops); j++ {
+			if ops[j].kind != ' ' {
+				end = j
+			} else if j-end > 2*diffContextLines {
+				break
+			}
+		}
+		stop := min(len(ops), end+diffContextLines+1)
+
+		countA, countB := 0, 0
+		for _, op := range ops[start:stop] {
+			if op.kind != '+' {
+				countA++
+			}
+			if op.kind != '-' {
+				countB++
+			}
+		}
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(ops[start].a, countA), hunkRange(ops[start].b, countB))
+
+		for _, op := range ops[start:stop] {
+			out.WriteByte(op.kind)
+			out.WriteString(op.line)
+			if !strings.HasSuffix(op.line, "\n") {
+				out.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		i = stop
+	}
+
+	return out.String()
+}
+
+// hunkRange formats the line range of a hunk header
+func hunkRange(start, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	default:
+		return fmt.Sprintf("%d,%d", start+1, count)
+	}
+}
+
+// diffLines computes the shortest edit script between two lists of lines using
+// the linear space variant of Myers' algorithm, which splits the texts at the
+// middle snake of the edit path
+func diffLines(a, b []string) []diffOp {
+	// SplitAfter leaves an empty last element when the text ends with a newline
+	if len(a) > 0 && a[len(a)-1] == "" {
+		a = a[:len(a)-1]
+	}
+	if len(b) > 0 && b[len(b)-1] == "" {
+		b = b[:len(b)-1]
+	}
+
+	var ops []diffOp
+	if !shareLines(a, b) {
+		// Completely different texts need no search
+		return appendReplace(ops, a, b, 0, len(a), 0, len(b))
+	}
+	return diffRange(ops, a, b, 0, len(a), 0, len(b))
+}
+
+// shareLines reports whether a and b have at least one line in common
+func shareLines(a, b []string) bool {
+	lines := make(map[string]struct{}, len(a))
+	for _, line := range a {
+		lines[line] = struct{}{}
+	}
+	for _, line := range b {
+		if _, ok := lines[line]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// diffRange appends the edit script between a[aLo:aHi] and b[bLo:bHi] to ops
+func diffRange(ops []diffOp, a, b []string, aLo, aHi, bLo, bHi int) []diffOp {
+	// Common prefix and suffix
+	for aLo < aHi && bLo < bHi && a[aLo] == b[bLo] {
+		ops = append(ops, diffOp{kind: ' ', line: a[aLo], a: aLo, b: bLo})
+		aLo++
+		bLo++
+	}
+	aEnd, bEnd := aHi, bHi
+	for aEnd > aLo && bEnd > bLo && a[aEnd-1] == b[bEnd-1] {
+		aEnd--
+		bEnd--
+	}
+
+	if x, y, ok := middleSnake(a, b, aLo, aEnd, bLo, bEnd); ok {
+		ops = diffRange(ops, a, b, aLo, x, bLo, y)
+		ops = diffRange(ops, a, b, x, aEnd, y, bEnd)
+	} else {
+		ops = appendReplace(ops, a, b, aLo, aEnd, bLo, bEnd)
+	}
+
+	for i := 0; i < aHi-aEnd; i++ {
+		ops = append(ops, diffOp{kind: ' ', line: a[aEnd+i], a: aEnd + i, b: bEnd + i})
+	}
+	return ops
+}
+
+// appendReplace appends the deletion of a[aLo:aHi] and the insertion of b[bLo:bHi]
+func appendReplace(ops []diffOp, a, b []string, aLo, aHi, bLo, bHi int) []diffOp {
+	for x := aLo; x < aHi; x++ {
+		ops = append(ops, diffOp{kind: '-', line: a[x], a: x, b: bLo})
+	}
+	for y := bLo; y < bHi; y++ {
+		ops = append(ops, diffOp{kind: '+', line: b[y], a: aHi, b: y})
+	}
+	return ops
+}
+
+// middleSnake searches the shortest edit path between a[aLo:aHi] and b[bLo:bHi]
+// from both ends at once, and returns the point where the two searches meet. It
+// reports false when the ranges cannot be split further: one of them is empty,
+// they have no line in common, or the search gave up after diffMaxCost edits.
+func middleSnake(a, b []string, aLo, aHi, bLo, bHi int) (int, int, bool) {
+	n, m := aHi-aLo, bHi-bLo
+	if n == 0 || m == 0 {
+		return 0, 0, false
+	}
+
+	maxD := (n + m + 1) / 2
+	offset := maxD + 1
+	// Furthest reaching x of every diagonal, forwards and backwards
+	forward := make([]int, 2*offset+1)
+	backward := make([]int, 2*offset+1)
+	for i := range forward {
+		forward[i] = -1
+		backward[i] = -1
+	}
+	forward[offset+1] = 0
+	backward[offset+1] = 0
+
+	delta := n - m
+	odd := delta%2 != 0
+	// Diagonals that went past the edges of the grid are not searched again
+	fStart, fEnd, bStart, bEnd := 0, 0, 0, 0
+	for d := 0; d < min(maxD, diffMaxCost); d++ {
+		for k := -d + fStart; k <= d-fEnd; k += 2 {
+			var x int
+			if k == -d || (k != d && forward[offset+k-1] < forward[offset+k+1]) {
+				x = forward[offset+k+1]
+			} else {
+				x = forward[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[aLo+x] == b[bLo+y] {
+				x++
+				y++
+			}
+			forward[offset+k] = x
+			switch {
+			case x > n:
+				fEnd += 2
+			case y > m:
+				fStart += 2
+			case odd:
+				if i := offset + delta - k; i >= 0 && i < len(backward) && backward[i] != -1 && x >= n-backward[i] {
+					return splitPoint(aLo, aHi, bLo, bHi, x, y)
+				}
+			}
+		}
+
+		for k := -d + bStart; k <= d-bEnd; k += 2 {
+			var x int
+			if k == -d || (k != d && backward[offset+k-1] < backward[offset+k+1]) {
+				x = backward[offset+k+1]
+			} else {
+				x = backward[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[aHi-x-1] == b[bHi-y-1] {
+				x++
+				y++
+			}
+			backward[offset+k] = x
+			switch {
+			case x > n:
+				bEnd += 2
+			case y > m:
+				bStart += 2
+			case !odd:
+				if i := offset + delta - k; i >= 0 && i < len(forward) && forward[i] != -1 {
+					fx := forward[i]
+					fy := fx - (i - offset)
+					if fx >= n-x {
+						return splitPoint(aLo, aHi, bLo, bHi, fx, fy)
+					}
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// splitPoint converts a point relative to the ranges into absolute indexes,
+// refusing the corners, which would not make the ranges smaller
+func splitPoint(aLo, aHi, bLo, bHi, x, y int) (int, int, bool) {
+	ax, by := aLo+x, bLo+y
+	if (ax == aLo && by == bLo) || (ax == aHi && by == bHi) {
+		return 0, 0, false
+	}
+	return ax, by, true
+}
@@ -0,0 +1,87 @@
+package lnkr
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []string
+	}{
+		{"both empty", nil, nil},
+		{"all insert", nil, []string{"a\n", "b\n", "c\n"}},
+		{"all delete", []string{"a\n", "b\n", "c\n"}, nil},
+		{"equal", []string{"a\n", "b\n"}, []string{"a\n", "b\n"}},
+		{"one common line", []string{"a\n", "x\n", "b\n"}, []string{"c\n", "x\n", "d\n"}},
+		{"nothing in common", []string{"a\n", "b\n"}, []string{"c\n", "d\n", "e\n"}},
+		{"insert in the middle", []string{"a\n", "c\n"}, []string{"a\n", "b\n", "c\n"}},
+		{"delete in the middle", []string{"a\n", "b\n", "c\n"}, []string{"a\n", "c\n"}},
+		{"missing final newline", []string{"a\n", "b"}, []string{"a\n", "b\n"}},
+	}
+
+	// Random texts over a small alphabet, so that lines repeat
+	rng := rand.New(rand.NewSource(1))
+	randomLines := func() []string {
+		lines := make([]string, rng.Intn(30))
+		for i := range lines {
+			lines[i] = fmt.Sprintf("%c\n", 'a'+rng.Intn(4))
+		}
+		return lines
+	}
+	for i := 0; i < 50; i++ {
+		tests = append(tests, struct {
+			name string
+			a, b []string
+		}{fmt.Sprintf("random %d", i), randomLines(), randomLines()})
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops := diffLines(tt.a, tt.b)
+
+			var gotA, gotB []string
+			edits := 0
+			for _, op := range ops {
+				if op.kind != '+' {
+					gotA = append(gotA, op.line)
+				}
+				if op.kind != '-' {
+					gotB = append(gotB, op.line)
+				}
+				if op.kind != ' ' {
+					edits++
+				}
+			}
+			if !slices.Equal(gotA, tt.a) {
+				t.Errorf("edit script rebuilds a as %q, want %q", gotA, tt.a)
+			}
+			if !slices.Equal(gotB, tt.b) {
+				t.Errorf("edit script rebuilds b as %q, want %q", gotB, tt.b)
+			}
+			if want := len(tt.a) + len(tt.b) - 2*lcsLength(tt.a, tt.b); edits != want {
+				t.Errorf("edit script has %d edits, want %d", edits, want)
+			}
+		})
+	}
+}
+
+// lcsLength returns the length of the longest common subsequence of a and b
+func lcsLength(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	for i := range a {
+		cur := make([]int, len(b)+1)
+		for j := range b {
+			if a[i] == b[j] {
+				cur[j+1] = prev[j] + 1
+			} else {
+				cur[j+1] = max(prev[j+1], cur[j])
+			}
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,395 @@
+package lnkr
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// doctorReport collects the results of the doctor checks
+type doctorReport struct {
+	failures int
+	warnings int
+}
+
+func (r *doctorReport) ok(format string, args ...interface{}) {
+	fmt.Printf("[OK]   %s\n", fmt.Sprintf(format, args...))
+}
+
+func (r *doctorReport) skip(format string, args ...interface{}) {
+	fmt.Printf("[SKIP] %s\n", fmt.Sprintf(format, args...))
+}
+
+func (r *doctorReport) warn(message, fix string) {
+	r.warnings++
+	fmt.Printf("[WARN] %s\n", message)
+	if fix != "" {
+		fmt.Printf("       fix: %s\n", fix)
+	}
+}
+
+func (r *doctorReport) fail(message, fix string) {
+	r.failures++
+	fmt.Printf("[FAIL] %s\n", message)
+	if fix != "" {
+		fmt.Printf("       fix: %s\n", fix)
+	}
+}
+
+// Doctor validates the environment, the user configuration and the project configuration
+// and prints actionable fixes for every problem found
+func Doctor() error {
+	report := &doctorReport{}
+
+	fmt.Println("Environment:")
+	checkEnvironment(report)
+
+	fmt.Println("\nUser configuration:")
+	userConfig := checkUserConfig(report)
+
+	fmt.Println("\nRemote path derivation:")
+	checkRemoteDerivation(report, userConfig)
+
+	fmt.Println("\nProject:")
+	checkProject(report)
+
+	fmt.Println()
+	if report.failures > 0 {
+		return fmt.Errorf("doctor found %d problem(s) and %d warning(s)", report.failures, report.warnings)
+	}
+	if report.warnings > 0 {
+		fmt.Printf("No problems found (%d warning(s)).\n", report.warnings)
+		return nil
+	}
+	fmt.Println("No problems found.")
+	return nil
+}
+
+func checkEnvironment(report *doctorReport) {
+	if value, set := os.LookupEnv(EnvRemoteDepth); set {
+		if _, err := parseRemoteDepth(value); err != nil {
+			report.fail(fmt.Sprintf("%s: %v", EnvRemoteDepth, err),
+				fmt.Sprintf("set it to a positive integer (export %s=%d) or unset it", EnvRemoteDepth, DefaultRemoteDepth))
+		} else {
+			report.ok("%s=%s", EnvRemoteDepth, value)
+		}
+	}
+
+	if value, set := os.LookupEnv(EnvRemoteRoot); set {
+		switch {
+		case value == "":
+			report.warn(fmt.Sprintf("%s is set but empty, the default remote root is used", EnvRemoteRoot), fmt.Sprintf("unset %s or set it to an absolute path", EnvRemoteRoot))
+		case !filepath.IsAbs(value):
+			report.fail(fmt.Sprintf("%s is not an absolute path: %s", EnvRemoteRoot, value),
+				fmt.Sprintf("export %s=$HOME/%s", EnvRemoteRoot, value))
+		default:
+			if info, err := os.Stat(value); err != nil || !info.IsDir() {
+				report.warn(fmt.Sprintf("%s does not exist: %s", EnvRemoteRoot, value),
+					fmt.Sprintf("mkdir -p %s (or use 'lnkr init --with-create-remote')", value))
+			} else {
+				report.ok("%s=%s", EnvRemoteRoot, value)
+			}
+		}
+	}
+
+	if value, set := os.LookupEnv(EnvRemoteLayout); set && value != "" {
+		if _, err := ResolveRemoteLayout(value, &UserConfig{}); err != nil {
+			report.fail(fmt.Sprintf("%s: %v", EnvRemoteLayout, err), fmt.Sprintf("export %s=%s", EnvRemoteLayout, RemoteLayoutDepth))
+		} else {
+			report.ok("%s=%s", EnvRemoteLayout, value)
+		}
+	}
+
+	if value, set := os.LookupEnv(EnvRemoteTemplate); set && value != "" {
+		if err := validateRemoteTemplate(value); err != nil {
+			report.fail(fmt.Sprintf("%s: %v", EnvRemoteTemplate, err),
+				fmt.Sprintf("use only the placeholders {%s}", strings.Join(templatePlaceholders, "}, {")))
+		} else {
+			report.ok("%s=%s", EnvRemoteTemplate, value)
+		}
+	}
+
+	if value, set := os.LookupEnv(EnvLinkType); set && value != "" {
+		if !isValidLinkType(value) {
+			report.fail(fmt.Sprintf("%s: invalid link type: %s", EnvLinkType, value),
+				fmt.Sprintf("export %s=%s (one of: %s)", EnvLinkType, LinkTypeHard, strings.Join(LinkTypes, ", ")))
+		} else {
+			report.ok("%s=%s", EnvLinkType, value)
+		}
+	}
+
+	if value, set := os.LookupEnv(EnvGitExcludePath); set && value != "" {
+		report.ok("%s=%s", EnvGitExcludePath, value)
+	}
+}
+
+func checkUserConfig(report *doctorReport) *UserConfig {
+	path, err := UserConfigPath()
+	if err != nil {
+		report.fail(fmt.Sprintf("cannot determine user configuration path: %v", err), "set HOME or XDG_CONFIG_HOME")
+		return &UserConfig{}
+	}
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		report.ok("%s not found, built-in defaults are used", path)
+		return &UserConfig{}
+	}
+
+	userConfig, err := LoadUserConfig()
+	if err != nil {
+		report.fail(err.Error(), fmt.Sprintf("fix the TOML syntax in %s", path))
+		return &UserConfig{}
+	}
+	report.ok("%s is valid TOML", path)
+
+	if userConfig.RemoteDepth < 0 {
+		report.fail(fmt.Sprintf("remote_depth must be a positive integer: %d", userConfig.RemoteDepth), fmt.Sprintf("set remote_depth = %d in %s", DefaultRemoteDepth, path))
+	}
+	if userConfig.RemoteLayout != "" {
+		if _, err := ResolveRemoteLayout(userConfig.RemoteLayout, userConfig); err != nil {
+			report.fail(fmt.Sprintf("remote_layout: %v", err), fmt.Sprintf("set remote_layout = %q in %s", RemoteLayoutDepth, path))
+		}
+	}
+	if userConfig.RemoteTemplate != "" {
+		if err := validateRemoteTemplate(userConfig.RemoteTemplate); err != nil {
+			report.fail(fmt.Sprintf("remote_template: %v", err), fmt.Sprintf("use only the placeholders {%s}", strings.Join(templatePlaceholders, "}, {")))
+		}
+	}
+	if userConfig.LinkType != "" && !isValidLinkType(userConfig.LinkType) {
+		report.fail(fmt.Sprintf("link_type: invalid link type: %s", userConfig.LinkType), fmt.Sprintf("set link_type = %q in %s", LinkTypeHard, path))
+	}
+	for _, pattern := range userConfig.Ignore {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			report.fail(fmt.Sprintf("ignore: invalid pattern %q: %v", pattern, err), fmt.Sprintf("fix or remove the pattern in %s", path))
+		}
+	}
+
+	return userConfig
+}
+
+func checkRemoteDerivation(report *doctorReport, userConfig *UserConfig) {
+	currentDir, err := os.Getwd()
+	if err != nil {
+		report.fail(fmt.Sprintf("failed to get current directory: %v", err), "")
+		return
+	}
+
+	baseDir, err := ResolveRemoteRoot(userConfig)
+	if err != nil {
+		report.fail(fmt.Sprintf("cannot resolve remote root: %v", err), fmt.Sprintf("set %s or remote_root in the user configuration", EnvRemoteRoot))
+		return
+	}
+
+	// Invalid depth and layout values are reported by the environment and user configuration checks
+	depth, err := ResolveRemoteDepth(userConfig)
+	if err != nil {
+		report.skip("remote depth is invalid")
+		return
+	}
+
+	layout, err := ResolveRemoteLayout("", userConfig)
+	if err != nil {
+		report.skip("remote layout is invalid")
+		return
+	}
+
+	if layout == RemoteLayoutDepth {
+		components := strings.Split(strings.Trim(currentDir, string(os.PathSeparator)), string(os.PathSeparator))
+		if depth > len(components) {
+			report.warn(fmt.Sprintf("remote depth %d is larger than the %d components of %s and will be clamped", depth, len(components), currentDir),
+				fmt.Sprintf("lower %s or remote_depth to %d or less", EnvRemoteDepth, len(components)))
+		}
+	}
+
+	remotePath, err := GetRemotePath(layout, currentDir, baseDir, depth, ResolveRemoteTemplate("", userConfig))
+	if err != nil {
+		report.fail(fmt.Sprintf("cannot derive remote path with layout %s: %v", layout, err),
+			"pass --remote to 'lnkr init' or choose another layout with --remote-layout")
+		return
+	}
+	report.ok("default remote path (%s layout): %s", layout, remotePath)
+}
+
+func checkProject(report *doctorReport) {
+	if _, err := os.Stat(ConfigFileName); os.IsNotExist(err) {
+		report.warn(fmt.Sprintf("%s not found in the current directory", ConfigFileName), "run 'lnkr init' (or 'lnkr init --from-remote' on a fresh clone)")
+		return
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		report.fail(fmt.Sprintf("failed to load %s: %v", ConfigFileName, err), fmt.Sprintf("fix the TOML syntax in %s", ConfigFileName))
+		return
+	}
+	report.ok("%s is valid TOML", ConfigFileName)
+
+	currentDir, err := os.Getwd()
+	if err != nil {
+		report.fail(fmt.Sprintf("failed to get current directory: %v", err), "")
+		return
+	}
+
+	// Local must be the project root, i.e. the directory holding .lnkr.toml
+	if config.Local == "" {
+		report.fail("local is not set", "run 'lnkr init' to set local to the project root")
+	} else if !samePath(config.Local, currentDir) {
+		report.fail(fmt.Sprintf("local (%s) is not the project root (%s)", config.Local, currentDir),
+			"run 'lnkr init' in the project root to rewrite local")
+	} else {
+		report.ok("local is the project root: %s", config.Local)
+	}
+
+	remoteUsable := checkRemote(report, config)
+	if remoteUsable && config.Local != "" {
+		checkHardLinks(report, config)
+	}
+
+	checkEncryptionKey(report, config)
+	checkIgnoreBackend(report, config, currentDir)
+}
+
+func checkRemote(report *doctorReport, config *Config) bool {
+	if config.Remote == "" {
+		report.fail("remote is not set", "run 'lnkr init --remote <path>'")
+		return false
+	}
+
+	info, err := os.Stat(config.Remote)
+	if os.IsNotExist(err) {
+		report.fail(fmt.Sprintf("remote does not exist: %s", config.Remote),
+			fmt.Sprintf("mkdir -p %s (or run 'lnkr init --remote %s --with-create-remote')", config.Remote, config.Remote))
+		return false
+	}
+	if err != nil {
+		report.fail(fmt.Sprintf("cannot access remote: %v", err), "check the permissions of the remote directory")
+		return false
+	}
+	if !info.IsDir() {
+		report.fail(fmt.Sprintf("remote is not a directory: %s", config.Remote), "run 'lnkr init --remote <path>' with a directory")
+		return false
+	}
+
+	probe, err := os.CreateTemp(config.Remote, ".lnkr-doctor-*")
+	if err != nil {
+		report.fail(fmt.Sprintf("remote is not writable: %v", err), fmt.Sprintf("chmod u+w %s", config.Remote))
+		return false
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+
+	report.ok("remote exists and is writable: %s", config.Remote)
+	return true
+}
+
+func checkHardLinks(report *doctorReport, config *Config) {
+	usesHardLinks := false
+	for _, link := range config.Links {
+		if link.Type == LinkTypeHard {
+			usesHardLinks = true
+			break
+		}
+	}
+
+	probe, err := os.CreateTemp(config.Remote, ".lnkr-doctor-*")
+	if err != nil {
+		report.fail(fmt.Sprintf("cannot create probe file in remote: %v", err), "")
+		return
+	}
+	probe.Close()
+	defer os.Remove(probe.Name())
+
+	linkPath := filepath.Join(config.Local, filepath.Base(probe.Name()))
+	if err := os.Link(probe.Name(), linkPath); err != nil {
+		message := fmt.Sprintf("hard links cannot be created between local and remote: %v", err)
+		fix := "put the remote on the same filesystem as the project, or use symbolic links ('lnkr add --symbolic')"
+		if usesHardLinks {
+			report.fail(message, fix)
+		} else {
+			report.warn(message, fix)
+		}
+		return
+	}
+	os.Remove(linkPath)
+
+	report.ok("hard links can be created between local and remote")
+}
+
+func checkEncryptionKey(report *doctorReport, config *Config) {
+	usesEncryption := false
+	for _, link := range config.Links {
+		if link.Type == LinkTypeEncrypted {
+			usesEncryption = true
+			break
+		}
+	}
+	if !usesEncryption {
+		return
+	}
+
+	if _, err := loadPassphrase(); err != nil {
+		report.fail(fmt.Sprintf("encrypted links cannot be decrypted: %v", err),
+			fmt.Sprintf("export %s=<passphrase>, or put it in the key file (%s or key_file in the user config)", EnvKey, EnvKeyFile))
+		return
+	}
+	report.ok("encryption key is available")
+}
+
+func checkIgnoreBackend(report *doctorReport, config *Config, currentDir string) {
+	switch backend := config.GetIgnoreBackend(); backend {
+	case IgnoreBackendGitExclude:
+		checkGitExcludePath(report, config, currentDir)
+	case IgnoreBackendNone:
+		report.warn("ignore backend is none; linked files are not excluded from version control",
+			fmt.Sprintf("set ignore_backend in %s to one of: %s", ConfigFileName, strings.Join(IgnoreBackends, ", ")))
+	default:
+		report.ok("ignore backend %s writes to %s", backend, config.GetIgnorePath())
+	}
+}
+
+func checkGitExcludePath(report *doctorReport, config *Config, currentDir string) {
+	excludePath := config.GetGitExcludePath()
+
+	gitDir, err := findGitDir(currentDir)
+	if err != nil {
+		report.fail(fmt.Sprintf("git exclude path %s is not inside a git repository: %v", excludePath, err),
+			"run 'git init' or set git_exclude_path in .lnkr.toml")
+		return
+	}
+
+	absExcludePath := excludePath
+	if !filepath.IsAbs(absExcludePath) {
+		absExcludePath = filepath.Join(currentDir, absExcludePath)
+	}
+
+	// info/exclude lives in the common directory shared by all worktrees
+	commonDir, err := gitCommonDir(gitDir)
+	if err != nil {
+		commonDir = gitDir
+	}
+
+	if !isInsideDir(absExcludePath, gitDir) && !isInsideDir(absExcludePath, commonDir) {
+		report.warn(fmt.Sprintf("git exclude path %s is outside the git directory %s; entries may be committed", excludePath, commonDir),
+			fmt.Sprintf("unset git_exclude_path in %s to use the resolved git exclude file", ConfigFileName))
+		return
+	}
+
+	report.ok("git exclude path is inside the git directory: %s", excludePath)
+}
+
+// isInsideDir reports whether path is inside dir
+func isInsideDir(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(os.PathSeparator))
+}
+
+// samePath reports whether two paths refer to the same location after resolving symlinks
+func samePath(a, b string) bool {
+	if resolved, err := filepath.EvalSymlinks(a); err == nil {
+		a = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(b); err == nil {
+		b = resolved
+	}
+	return filepath.Clean(a) == filepath.Clean(b)
+}
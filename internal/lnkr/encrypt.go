@@ -0,0 +1,257 @@
+package lnkr
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Header of the files written by the encrypted link type, followed by the salt,
+// the nonce and the AES-256-GCM ciphertext
+const encryptedMagic = "LNKR1"
+
+// Key derivation parameters
+const (
+	encryptionSaltSize   = 16
+	encryptionKeySize    = 32
+	encryptionIterations = 600000
+)
+
+// Default key file in the user configuration directory
+const KeyFileName = "key"
+
+// Mode of the decrypted local files unless the link sets its own
+const encryptedLocalMode = "0600"
+
+// Passphrase read by loadPassphrase, and the keys derived from it per salt
+var (
+	passphrase  []byte
+	derivedKeys = make(map[string][]byte)
+)
+
+// loadPassphrase returns the passphrase of the encrypted links.
+// Precedence: LNKR_KEY > LNKR_KEY_FILE > key_file in user config > $XDG_CONFIG_HOME/lnkr/key
+func loadPassphrase() ([]byte, error) {
+	if passphrase != nil {
+		return passphrase, nil
+	}
+
+	if key := os.Getenv(EnvKey); key != "" {
+		passphrase = []byte(key)
+		return passphrase, nil
+	}
+
+	path, err := resolveKeyFile()
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no encryption key: set %s or create the key file %s", EnvKey, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+
+	key := strings.TrimSpace(string(content))
+	if key == "" {
+		return nil, fmt.Errorf("key file is empty: %s", path)
+	}
+	passphrase = []byte(key)
+	return passphrase, nil
+}
+
+// resolveKeyFile returns the path of the key file
+func resolveKeyFile() (string, error) {
+	if path := os.Getenv(EnvKeyFile); path != "" {
+		return expandHome(path)
+	}
+
+	userConfig, err := LoadUserConfig()
+	if err != nil {
+		return "", err
+	}
+	if userConfig.KeyFile != "" {
+		return expandHome(userConfig.KeyFile)
+	}
+
+	dir, err := UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, KeyFileName), nil
+}
+
+// deriveKey derives the AES key for a salt from the passphrase
+func deriveKey(salt []byte) ([]byte, error) {
+	if key, ok := derivedKeys[string(salt)]; ok {
+		return key, nil
+	}
+
+	secret, err := loadPassphrase()
+	if err != nil {
+		return nil, err
+	}
+	key, err := pbkdf2.Key(sha256.New, string(secret), salt, encryptionIterations, encryptionKeySize)
+	if err != nil {
+		return nil, err
+	}
+	derivedKeys[string(salt)] = key
+	return key, nil
+}
+
+// newGCM returns the AES-GCM cipher for a salt
+func newGCM(salt []byte) (cipher.AEAD, error) {
+	key, err := deriveKey(salt)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encryptContent encrypts content with a fresh salt and nonce
+func encryptContent(plain []byte) ([]byte, error) {
+	salt := make([]byte, encryptionSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	header := append([]byte(encryptedMagic), salt...)
+	header = append(header, nonce...)
+	// The header is authenticated along with the content
+	return append(header, gcm.Seal(nil, nonce, plain, header)...), nil
+}
+
+// decryptContent decrypts content written by encryptContent
+func decryptContent(blob []byte) ([]byte, error) {
+	if !bytes.HasPrefix(blob, []byte(encryptedMagic)) {
+		return nil, fmt.Errorf("not encrypted by lnkr")
+	}
+	if len(blob) < len(encryptedMagic)+encryptionSaltSize {
+		return nil, fmt.Errorf("truncated encrypted file")
+	}
+	salt := blob[len(encryptedMagic) : len(encryptedMagic)+encryptionSaltSize]
+
+	gcm, err := newGCM(salt)
+	if err != nil {
+		return nil, err
+	}
+	headerSize := len(encryptedMagic) + encryptionSaltSize + gcm.NonceSize()
+	if len(blob) < headerSize+gcm.Overhead() {
+		return nil, fmt.Errorf("truncated encrypted file")
+	}
+	nonce := blob[len(encryptedMagic)+encryptionSaltSize : headerSize]
+
+	plain, err := gcm.Open(nil, nonce, blob[headerSize:], blob[:headerSize])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt (wrong key or corrupted file)")
+	}
+	return plain, nil
+}
+
+// decryptFile returns the decrypted content of an encrypted remote file
+func decryptFile(remoteAbs string) ([]byte, error) {
+	blob, err := os.ReadFile(remoteAbs)
+	if err != nil {
+		return nil, err
+	}
+	return decryptContent(blob)
+}
+
+// encryptFile encrypts a local file into the remote
+func encryptFile(localAbs, remoteAbs string) error {
+	plain, err := os.ReadFile(localAbs)
+	if err != nil {
+		return err
+	}
+	blob, err := encryptContent(plain)
+	if err != nil {
+		return err
+	}
+	journalTouch(remoteAbs)
+	if err := os.MkdirAll(filepath.Dir(remoteAbs), 0755); err != nil {
+		return fmt.Errorf("failed to create remote directory: %w", err)
+	}
+	return writeFileAtomic(remoteAbs, blob, 0600)
+}
+
+// linkEncrypted creates the local or remote side of an encrypted link: local
+// files are encrypted into a missing remote, and the remote is decrypted into a
+// missing local file. Local edits are never overwritten; 'lnkr sync' encrypts them.
+func linkEncrypted(link Link, config *Config) error {
+	absRemote, err := filepath.Abs(config.Remote)
+	if err != nil {
+		return fmt.Errorf("invalid remote directory path: %w", err)
+	}
+	localAbs := filepath.Join(config.Local, link.Path)
+	remoteAbs := filepath.Join(absRemote, link.Path)
+
+	_, localErr := os.Stat(localAbs)
+	_, remoteErr := os.Stat(remoteAbs)
+	switch {
+	case os.IsNotExist(localErr) && os.IsNotExist(remoteErr):
+		return fmt.Errorf("source path does not exist: %s", localAbs)
+	case os.IsNotExist(remoteErr):
+		if err := encryptFile(localAbs, remoteAbs); err != nil {
+			return fmt.Errorf("failed to encrypt: %w", err)
+		}
+		fmt.Printf("Encrypted: %s -> %s\n", localAbs, remoteAbs)
+		return nil
+	}
+
+	plain, err := decryptFile(remoteAbs)
+	if err != nil {
+		return err
+	}
+
+	if os.IsNotExist(localErr) {
+		journalTouchDecrypted(localAbs)
+		if err := os.MkdirAll(filepath.Dir(localAbs), 0755); err != nil {
+			return fmt.Errorf("failed to create local directory: %w", err)
+		}
+		if err := writeFileAtomic(localAbs, plain, 0600); err != nil {
+			return fmt.Errorf("failed to write decrypted file: %w", err)
+		}
+		fmt.Printf("Decrypted: %s -> %s\n", remoteAbs, localAbs)
+		return nil
+	}
+
+	current, err := os.ReadFile(localAbs)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(current, plain) {
+		fmt.Printf("Warning: %s differs from the encrypted remote. Run 'lnkr sync' to encrypt local edits or 'lnkr diff' to compare\n", localAbs)
+	}
+	return nil
+}
+
+// encryptedStatus describes an encrypted link whose local copy differs from the
+// remote, telling which side changed last
+func encryptedStatus(localAbs, remoteAbs string) string {
+	localInfo, localErr := os.Stat(localAbs)
+	remoteInfo, remoteErr := os.Stat(remoteAbs)
+	if localErr == nil && remoteErr == nil && remoteInfo.ModTime().After(localInfo.ModTime()) {
+		return "Decrypted copy is outdated"
+	}
+	return "Local changes not encrypted"
+}
@@ -0,0 +1,205 @@
+package lnkr
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ExcludeSync regenerates the LNKR section of the ignore file from .lnkr.toml
+func ExcludeSync() error {
+	config, err := loadExistingConfig()
+	if err != nil {
+		return err
+	}
+
+	if config.GetIgnorePath() == "" {
+		fmt.Printf("Ignore backend is %s; no ignore file is maintained\n", IgnoreBackendNone)
+		return nil
+	}
+
+	changed, err := syncIgnoreFile(config)
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %w", config.GetIgnorePath(), err)
+	}
+	if !changed {
+		fmt.Printf("%s is already in sync\n", config.GetIgnorePath())
+	}
+	return nil
+}
+
+// ExcludeShow prints the entries of the LNKR section of the ignore file
+func ExcludeShow() error {
+	config, err := loadExistingConfig()
+	if err != nil {
+		return err
+	}
+
+	writer, err := newIgnoreWriter(config)
+	if err != nil {
+		return err
+	}
+	if writer.Path() == "" {
+		fmt.Printf("Ignore backend is %s; no ignore file is maintained\n", IgnoreBackendNone)
+		return nil
+	}
+
+	patterns, found, err := writer.ReadSection()
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", writer.Path(), err)
+	}
+	if !found {
+		fmt.Printf("No LNKR section found in %s\n", writer.Path())
+		return nil
+	}
+
+	for _, pattern := range patterns {
+		fmt.Println(pattern)
+	}
+	return nil
+}
+
+// ExcludeCheck reports entries missing from, or stale in, the LNKR section of the ignore file
+func ExcludeCheck() error {
+	config, err := loadExistingConfig()
+	if err != nil {
+		return err
+	}
+
+	writer, err := newIgnoreWriter(config)
+	if err != nil {
+		return err
+	}
+	if writer.Path() == "" {
+		fmt.Printf("Ignore backend is %s; no ignore file is maintained\n", IgnoreBackendNone)
+		return nil
+	}
+
+	current, _, err := writer.ReadSection()
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", writer.Path(), err)
+	}
+
+	missing, stale := compareEntries(current, expectedIgnorePatterns(writer, config))
+	for _, pattern := range missing {
+		fmt.Printf("Missing: %s\n", pattern)
+	}
+	for _, pattern := range stale {
+		fmt.Printf("Stale:   %s\n", pattern)
+	}
+
+	if len(missing) > 0 || len(stale) > 0 {
+		return fmt.Errorf("%s is out of sync with %s (%d missing, %d stale). Run 'lnkr exclude sync'", writer.Path(), ConfigFileName, len(missing), len(stale))
+	}
+
+	fmt.Printf("%s is in sync with %s\n", writer.Path(), ConfigFileName)
+	return nil
+}
+
+// syncIgnoreFile regenerates the LNKR section of the ignore file so that it holds
+// exactly .lnkr.toml and the configured link paths. It reports whether the file
+// was changed.
+func syncIgnoreFile(config *Config) (bool, error) {
+	writer, err := newIgnoreWriter(config)
+	if err != nil {
+		return false, err
+	}
+	if writer.Path() == "" {
+		return false, nil
+	}
+
+	// Patterns are compared as written, so entries that were not escaped are rewritten
+	current, found, err := writer.ReadSection()
+	if err != nil {
+		return false, err
+	}
+
+	added, removed := compareEntries(current, expectedIgnorePatterns(writer, config))
+	if found && len(added) == 0 && len(removed) == 0 {
+		return false, nil
+	}
+
+	if err := writer.WriteSection(expectedIgnorePaths(config)); err != nil {
+		return false, err
+	}
+
+	switch len(added) {
+	case 0:
+	case 1:
+		fmt.Printf("Added %s to %s\n", added[0], writer.Path())
+	default:
+		fmt.Printf("Added %d entries to %s\n", len(added), writer.Path())
+	}
+	switch len(removed) {
+	case 0:
+	case 1:
+		fmt.Printf("Removed %s from %s\n", removed[0], writer.Path())
+	default:
+		fmt.Printf("Removed %d entries from %s\n", len(removed), writer.Path())
+	}
+	return true, nil
+}
+
+// updateIgnoreFile keeps the ignore file in sync with the links once .lnkr.toml
+// is saved. A failure is only reported, as the configuration is already written.
+func updateIgnoreFile(config *Config) {
+	if _, err := syncIgnoreFile(config); err != nil {
+		fmt.Printf("Warning: failed to update %s: %v\n", config.GetIgnorePath(), err)
+	}
+}
+
+// clearPreviousIgnoreFile removes the LNKR section from the ignore file used before
+// a configuration change, when the change moved the section to another file
+func clearPreviousIgnoreFile(previous IgnoreWriter, config *Config) {
+	if previous == nil || previous.Path() == "" || previous.Path() == config.GetIgnorePath() {
+		return
+	}
+	if _, found, err := previous.ReadSection(); err != nil || !found {
+		return
+	}
+	if err := previous.WriteSection(nil); err != nil {
+		fmt.Printf("Warning: failed to remove LNKR section from %s: %v\n", previous.Path(), err)
+		return
+	}
+	fmt.Printf("Removed LNKR section from %s\n", previous.Path())
+}
+
+// expectedIgnorePaths returns the sorted paths the LNKR section should hold
+func expectedIgnorePaths(config *Config) []string {
+	paths := []string{ConfigFileName}
+	for _, link := range config.Links {
+		paths = append(paths, link.Path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// expectedIgnorePatterns returns the patterns the LNKR section should hold
+func expectedIgnorePatterns(writer IgnoreWriter, config *Config) []string {
+	paths := expectedIgnorePaths(config)
+	patterns := make([]string, len(paths))
+	for i, path := range paths {
+		patterns[i] = writer.Pattern(path)
+	}
+	return patterns
+}
+
+// compareEntries returns the expected entries missing from current and the current entries not expected
+func compareEntries(current, expected []string) (missing, stale []string) {
+	currentSet := make(map[string]struct{}, len(current))
+	for _, entry := range current {
+		currentSet[entry] = struct{}{}
+	}
+	expectedSet := make(map[string]struct{}, len(expected))
+	for _, entry := range expected {
+		expectedSet[entry] = struct{}{}
+		if _, ok := currentSet[entry]; !ok {
+			missing = append(missing, entry)
+		}
+	}
+	for _, entry := range current {
+		if _, ok := expectedSet[entry]; !ok {
+			stale = append(stale, entry)
+		}
+	}
+	return missing, stale
+}
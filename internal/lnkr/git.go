@@ -0,0 +1,215 @@
+package lnkr
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// findGitDir walks up from dir and returns the git directory of the nearest repository.
+// A .git file (used by worktrees and submodules) is followed to the directory named
+// by its "gitdir:" line.
+func findGitDir(dir string) (string, error) {
+	_, gitDir, err := findWorkTree(dir)
+	return gitDir, err
+}
+
+// findWorkTree walks up from dir and returns the top-level directory of the nearest
+// repository (the one holding .git) together with its git directory
+func findWorkTree(dir string) (string, string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", "", err
+	}
+
+	for {
+		gitPath := filepath.Join(dir, ".git")
+		if info, err := os.Stat(gitPath); err == nil {
+			if info.IsDir() {
+				return dir, gitPath, nil
+			}
+			gitDir, err := readGitDirFile(gitPath)
+			return dir, gitDir, err
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", "", fmt.Errorf("not a git repository (or any of the parent directories): %s", dir)
+		}
+		dir = parent
+	}
+}
+
+// readGitDirFile resolves a .git file of the form "gitdir: <path>"
+func readGitDirFile(gitFile string) (string, error) {
+	content, err := os.ReadFile(gitFile)
+	if err != nil {
+		return "", err
+	}
+
+	line := strings.TrimSpace(strings.SplitN(string(content), "\n", 2)[0])
+	gitDir, found := strings.CutPrefix(line, "gitdir:")
+	if !found {
+		return "", fmt.Errorf("invalid gitfile format: %s", gitFile)
+	}
+
+	gitDir = strings.TrimSpace(gitDir)
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(filepath.Dir(gitFile), gitDir)
+	}
+	gitDir = filepath.Clean(gitDir)
+
+	if info, err := os.Stat(gitDir); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("gitdir in %s does not exist: %s", gitFile, gitDir)
+	}
+	return gitDir, nil
+}
+
+// gitCommonDir returns the directory shared by all worktrees of a repository,
+// as named by the "commondir" file of a worktree git directory
+func gitCommonDir(gitDir string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(gitDir, "commondir"))
+	if os.IsNotExist(err) {
+		return gitDir, nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	commonDir := strings.TrimSpace(string(content))
+	if !filepath.IsAbs(commonDir) {
+		commonDir = filepath.Join(gitDir, commonDir)
+	}
+	return filepath.Clean(commonDir), nil
+}
+
+// Paths under the git directory that are shared by all worktrees, and the
+// exceptions below them that stay per-worktree (see gitrepository-layout(5))
+var (
+	gitCommonPaths     = []string{"branches", "config", "hooks", "info", "logs", "objects", "packed-refs", "refs", "remotes", "rr-cache", "shallow", "worktrees"}
+	gitPerWorktreeDirs = []string{"info/sparse-checkout", "logs/HEAD", "refs/bisect", "refs/rewritten", "refs/worktree"}
+)
+
+// gitPath resolves a path inside the git directory of the repository containing dir,
+// like 'git rev-parse --git-path <name>', without running git
+func gitPath(dir, name string) (string, error) {
+	gitDir, err := findGitDir(dir)
+	if err != nil {
+		return "", err
+	}
+
+	name = filepath.ToSlash(filepath.Clean(name))
+	if isGitCommonPath(name) {
+		commonDir, err := gitCommonDir(gitDir)
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(commonDir, filepath.FromSlash(name)), nil
+	}
+	return filepath.Join(gitDir, filepath.FromSlash(name)), nil
+}
+
+// isGitCommonPath reports whether a path inside the git directory is shared by all worktrees
+func isGitCommonPath(name string) bool {
+	for _, p := range gitPerWorktreeDirs {
+		if name == p || strings.HasPrefix(name, p+"/") {
+			return false
+		}
+	}
+	for _, p := range gitCommonPaths {
+		if name == p || strings.HasPrefix(name, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// readGitRemoteURL returns the url of the named remote from the repository's git config
+func readGitRemoteURL(dir, remote string) (string, error) {
+	url, found, err := readGitConfigValue(dir, fmt.Sprintf(`remote "%s"`, remote), "url")
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return "", fmt.Errorf("remote %q not found in git config", remote)
+	}
+	return url, nil
+}
+
+// readGitConfigValue returns the value of a key in a section (e.g. remote "origin")
+// of the repository's git config, and whether it is set
+func readGitConfigValue(dir, section, key string) (string, bool, error) {
+	configPath, err := gitPath(dir, "config")
+	if err != nil {
+		return "", false, err
+	}
+
+	file, err := os.Open(configPath)
+	if err != nil {
+		return "", false, err
+	}
+	defer file.Close()
+
+	header := "[" + section + "]"
+	inSection := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			inSection = strings.EqualFold(line, header)
+			continue
+		}
+		if !inSection {
+			continue
+		}
+		k, value, found := strings.Cut(line, "=")
+		if found && strings.EqualFold(strings.TrimSpace(k), key) {
+			return strings.Trim(strings.TrimSpace(value), `"`), true, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", false, err
+	}
+
+	return "", false, nil
+}
+
+// parseGitRemoteURL splits a git remote url into host, owner and repository name.
+// Both URL forms (https://host/owner/repo.git, ssh://git@host:22/owner/repo)
+// and scp-like forms (git@host:owner/repo.git) are supported.
+func parseGitRemoteURL(rawURL string) (host, owner, repo string, err error) {
+	var path string
+	if scheme, rest, found := strings.Cut(rawURL, "://"); found {
+		if scheme == "file" {
+			return "", "", "", fmt.Errorf("local remote url is not supported: %s", rawURL)
+		}
+		hostPart, pathPart, _ := strings.Cut(rest, "/")
+		if i := strings.LastIndex(hostPart, "@"); i != -1 {
+			hostPart = hostPart[i+1:]
+		}
+		if h, _, found := strings.Cut(hostPart, ":"); found {
+			hostPart = h
+		}
+		host, path = hostPart, pathPart
+	} else if hostPart, pathPart, found := strings.Cut(rawURL, ":"); found && !strings.Contains(hostPart, "/") {
+		if i := strings.LastIndex(hostPart, "@"); i != -1 {
+			hostPart = hostPart[i+1:]
+		}
+		host, path = hostPart, pathPart
+	} else {
+		return "", "", "", fmt.Errorf("local remote url is not supported: %s", rawURL)
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	i := strings.LastIndex(path, "/")
+	if host == "" || i <= 0 || i == len(path)-1 {
+		return "", "", "", fmt.Errorf("cannot determine owner and repository from url: %s", rawURL)
+	}
+
+	return strings.ToLower(host), path[:i], path[i+1:], nil
+}
@@ -0,0 +1,176 @@
+package lnkr
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Signature of the git index file
+const gitIndexSignature = "DIRC"
+
+// Size of the fixed part of an index entry before the object name:
+// ctime, mtime, dev, ino, mode, uid, gid and size
+const gitIndexStatSize = 40
+
+// Index entry flags
+const (
+	gitIndexFlagExtended = 0x4000
+	gitIndexNameMask     = 0x0fff
+)
+
+// gitIndex holds the paths tracked by a repository, relative to its top-level directory
+type gitIndex struct {
+	// Top-level directory of the work tree
+	root string
+	// Sorted slash-separated paths of the index entries
+	paths []string
+}
+
+// readGitIndex parses the index of the repository containing dir.
+// It returns nil when dir is not inside a git repository.
+func readGitIndex(dir string) (*gitIndex, error) {
+	root, gitDir, err := findWorkTree(dir)
+	if err != nil {
+		return nil, nil
+	}
+
+	index := &gitIndex{root: root}
+
+	content, err := os.ReadFile(filepath.Join(gitDir, "index"))
+	if os.IsNotExist(err) {
+		// Nothing has been staged yet
+		return index, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	hashSize := 20
+	if format, _, _ := readGitConfigValue(dir, "extensions", "objectformat"); strings.EqualFold(format, "sha256") {
+		hashSize = 32
+	}
+
+	index.paths, err = parseGitIndex(content, hashSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Join(gitDir, "index"), err)
+	}
+	sort.Strings(index.paths)
+	return index, nil
+}
+
+// parseGitIndex returns the paths of the entries of a version 2, 3 or 4 index file
+func parseGitIndex(content []byte, hashSize int) ([]string, error) {
+	if len(content) < 12 || string(content[:4]) != gitIndexSignature {
+		return nil, fmt.Errorf("not a git index file")
+	}
+	version := binary.BigEndian.Uint32(content[4:8])
+	if version < 2 || version > 4 {
+		return nil, fmt.Errorf("unsupported index version %d", version)
+	}
+	count := binary.BigEndian.Uint32(content[8:12])
+
+	paths := make([]string, 0, count)
+	offset := 12
+	previous := ""
+	for i := uint32(0); i < count; i++ {
+		start := offset
+		offset += gitIndexStatSize + hashSize
+		if offset+2 > len(content) {
+			return nil, fmt.Errorf("truncated entry %d", i)
+		}
+		flags := binary.BigEndian.Uint16(content[offset : offset+2])
+		offset += 2
+		if version >= 3 && flags&gitIndexFlagExtended != 0 {
+			offset += 2
+		}
+		if offset > len(content) {
+			return nil, fmt.Errorf("truncated entry %d", i)
+		}
+
+		var name string
+		if version == 4 {
+			// Names are prefix-compressed against the previous entry
+			strip, n := gitIndexVarint(content[offset:])
+			if n == 0 || strip > len(previous) {
+				return nil, fmt.Errorf("invalid path prefix in entry %d", i)
+			}
+			offset += n
+			end := bytes.IndexByte(content[offset:], 0)
+			if end == -1 {
+				return nil, fmt.Errorf("truncated path in entry %d", i)
+			}
+			name = previous[:len(previous)-strip] + string(content[offset:offset+end])
+			offset += end + 1
+		} else {
+			// Names are NUL-terminated and entries padded to a multiple of 8 bytes
+			end := bytes.IndexByte(content[offset:], 0)
+			if end == -1 {
+				return nil, fmt.Errorf("truncated path in entry %d", i)
+			}
+			if length := int(flags & gitIndexNameMask); length < gitIndexNameMask && length != end {
+				return nil, fmt.Errorf("path length mismatch in entry %d", i)
+			}
+			name = string(content[offset : offset+end])
+			offset = start + (offset-start+end+8)/8*8
+		}
+
+		// Entries with several stages (merge conflicts) share a path
+		if name != previous || len(paths) == 0 {
+			paths = append(paths, name)
+		}
+		previous = name
+	}
+	return paths, nil
+}
+
+// gitIndexVarint decodes the offset encoding used by index version 4 and returns
+// the value and the number of bytes read (0 when the input is truncated)
+func gitIndexVarint(data []byte) (int, int) {
+	if len(data) == 0 {
+		return 0, 0
+	}
+	value := int(data[0] & 0x7f)
+	n := 1
+	for data[n-1]&0x80 != 0 {
+		if n >= len(data) {
+			return 0, 0
+		}
+		value = ((value + 1) << 7) | int(data[n]&0x7f)
+		n++
+	}
+	return value, n
+}
+
+// tracked returns the tracked paths at or under an absolute path, relative to the work tree
+func (index *gitIndex) tracked(absPath string) []string {
+	rel, err := filepath.Rel(index.root, absPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(os.PathSeparator)) {
+		return nil
+	}
+	rel = filepath.ToSlash(rel)
+
+	// A sparse index stores collapsed directories with a trailing slash
+	for dir := path.Dir(rel); dir != "."; dir = path.Dir(dir) {
+		if i := sort.SearchStrings(index.paths, dir+"/"); i < len(index.paths) && index.paths[i] == dir+"/" {
+			return []string{dir + "/"}
+		}
+	}
+
+	var matches []string
+	i := sort.SearchStrings(index.paths, rel)
+	for ; i < len(index.paths); i++ {
+		p := index.paths[i]
+		if p == rel || strings.HasPrefix(p, rel+"/") {
+			matches = append(matches, p)
+		} else if p > rel+"/" {
+			break
+		}
+	}
+	return matches
+}
@@ -0,0 +1,224 @@
+package lnkr
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Name of the git hook installed by lnkr
+const preCommitHookName = "pre-commit"
+
+// Marker identifying hooks installed by lnkr
+const hookMarker = "# Installed by lnkr"
+
+// preCommitHookScript returns the body of the pre-commit hook installed by lnkr.
+// Git runs hooks from the top of the work tree, so the hook changes to the
+// project directory, which may be a subdirectory, first.
+func preCommitHookScript(projectDir string) string {
+	dir := shellQuote(projectDir)
+	return `#!/bin/sh
+` + hookMarker + `: refuses to commit files managed by lnkr.
+# Remove it with 'lnkr hook uninstall'.
+cd ` + dir + ` || { echo "lnkr: project directory ` + dir + ` not found. Run 'lnkr hook install' again" >&2; exit 1; }
+exec lnkr hook run
+`
+}
+
+// shellQuote quotes s for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// HookInstall installs the pre-commit hook that refuses to commit lnkr-managed files.
+// A hook that was not installed by lnkr is only replaced with force.
+func HookInstall(force bool) error {
+	hookPath, err := hookFilePath(preCommitHookName)
+	if err != nil {
+		return err
+	}
+	projectDir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	script := preCommitHookScript(projectDir)
+
+	content, err := os.ReadFile(hookPath)
+	switch {
+	case err == nil && isLnkrHook(content):
+		if string(content) == script {
+			fmt.Printf("%s hook is already installed: %s\n", preCommitHookName, hookPath)
+			return nil
+		}
+	case err == nil && !force:
+		return fmt.Errorf("%s already exists and was not installed by lnkr. Use --force to replace it", hookPath)
+	case err != nil && !os.IsNotExist(err):
+		return fmt.Errorf("failed to read %s: %w", hookPath, err)
+	}
+
+	journalTouch(hookPath)
+	if err := os.MkdirAll(filepath.Dir(hookPath), 0755); err != nil {
+		return fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+	if err := os.WriteFile(hookPath, []byte(script), 0755); err != nil {
+		return fmt.Errorf("failed to write %s: %w", hookPath, err)
+	}
+	// WriteFile keeps the mode of an existing file
+	if err := os.Chmod(hookPath, 0755); err != nil {
+		return fmt.Errorf("failed to make %s executable: %w", hookPath, err)
+	}
+
+	fmt.Printf("Installed %s hook: %s\n", preCommitHookName, hookPath)
+	return nil
+}
+
+// HookUninstall removes the pre-commit hook installed by lnkr
+func HookUninstall() error {
+	hookPath, err := hookFilePath(preCommitHookName)
+	if err != nil {
+		return err
+	}
+
+	content, err := os.ReadFile(hookPath)
+	if os.IsNotExist(err) {
+		fmt.Printf("%s hook is not installed\n", preCommitHookName)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", hookPath, err)
+	}
+	if !isLnkrHook(content) {
+		return fmt.Errorf("%s was not installed by lnkr; remove it by hand", hookPath)
+	}
+
+	journalTouch(hookPath)
+	if err := os.Remove(hookPath); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", hookPath, err)
+	}
+
+	fmt.Printf("Removed %s hook: %s\n", preCommitHookName, hookPath)
+	return nil
+}
+
+// hookFilePath returns the path of a git hook. Git resolves it so that core.hooksPath,
+// as set by hook managers such as husky or lefthook, is honoured.
+func hookFilePath(name string) (string, error) {
+	output, err := gitOutput("rev-parse", "--git-path", "hooks/"+name)
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the git hooks directory: %w", err)
+	}
+
+	path := strings.TrimSpace(output)
+	if path == "" {
+		return "", fmt.Errorf("failed to locate the git hooks directory")
+	}
+	return filepath.Abs(filepath.FromSlash(path))
+}
+
+// HookRun checks the staged files against the configured links and fails if any
+// lnkr-managed file is about to be committed. It is the body of the pre-commit hook.
+func HookRun() error {
+	// Nothing is managed in a project without .lnkr.toml
+	if _, err := os.Stat(ConfigFileName); os.IsNotExist(err) {
+		return nil
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	staged, err := stagedFiles()
+	if err != nil {
+		return err
+	}
+	localAbs, err := filepath.Abs(config.Local)
+	if err != nil {
+		return fmt.Errorf("invalid local directory path: %w", err)
+	}
+	// Git reports the top of the work tree with symbolic links resolved
+	if resolved, err := filepath.EvalSymlinks(localAbs); err == nil {
+		localAbs = resolved
+	}
+
+	var blocked []string
+	for _, path := range staged {
+		// Staged paths are relative to the top of the work tree, links to the project
+		rel, err := filepath.Rel(localAbs, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(os.PathSeparator)) {
+			continue
+		}
+		if isManagedPath(config, rel) {
+			blocked = append(blocked, rel)
+		}
+	}
+	if len(blocked) == 0 {
+		return nil
+	}
+
+	fmt.Fprintf(os.Stderr, "lnkr: refusing to commit %d file(s) managed by lnkr:\n", len(blocked))
+	for _, path := range blocked {
+		fmt.Fprintf(os.Stderr, "  %s\n", path)
+	}
+	fmt.Fprintln(os.Stderr, "Unstage them with 'git rm --cached <path>' (the files stay on disk),")
+	fmt.Fprintln(os.Stderr, "then run 'lnkr exclude sync' so they stay out of future commits.")
+	return fmt.Errorf("commit blocked: %d lnkr-managed file(s) staged", len(blocked))
+}
+
+// stagedFiles returns the absolute paths staged for commit, excluding deletions
+func stagedFiles() ([]string, error) {
+	top, err := gitOutput("rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate the top of the work tree: %w", err)
+	}
+	output, err := gitOutput("diff", "--cached", "--name-only", "-z", "--diff-filter=d")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list staged files: %w", err)
+	}
+
+	top = filepath.FromSlash(strings.TrimSpace(top))
+	if resolved, err := filepath.EvalSymlinks(top); err == nil {
+		top = resolved
+	}
+	var paths []string
+	for _, path := range strings.Split(output, "\x00") {
+		if path != "" {
+			paths = append(paths, filepath.Join(top, filepath.FromSlash(path)))
+		}
+	}
+	return paths, nil
+}
+
+// gitOutput runs git in the current directory and returns its output
+func gitOutput(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return string(output), nil
+}
+
+// isManagedPath reports whether a path relative to the project root is .lnkr.toml,
+// a link, or inside a directory link
+func isManagedPath(config *Config, path string) bool {
+	if path == ConfigFileName {
+		return true
+	}
+	for _, link := range config.Links {
+		if path == link.Path || strings.HasPrefix(path, link.Path+string(os.PathSeparator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isLnkrHook reports whether a hook script was installed by lnkr
+func isLnkrHook(content []byte) bool {
+	return bytes.Contains(content, []byte(hookMarker))
+}
@@ -0,0 +1,85 @@
+package lnkr
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHookRunProjectInSubdirectory(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repo := t.TempDir()
+	project := filepath.Join(repo, "sub", "project")
+	if err := os.MkdirAll(filepath.Join(project, "dir"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, repo, "init", "-q")
+
+	writeTestFile(t, filepath.Join(project, ConfigFileName), `version = 1
+local = "`+project+`"
+remote = "`+filepath.Join(t.TempDir(), "remote")+`"
+
+[[links]]
+path = "secret.env"
+type = "hard"
+
+[[links]]
+path = "dir"
+type = "symbolic"
+`)
+	writeTestFile(t, filepath.Join(project, "secret.env"), "TOKEN=x\n")
+	writeTestFile(t, filepath.Join(project, "dir", "inner.txt"), "inner\n")
+	writeTestFile(t, filepath.Join(project, "main.go"), "package main\n")
+	// Same name as a link, but outside the project
+	writeTestFile(t, filepath.Join(repo, "secret.env"), "PUBLIC=1\n")
+
+	// Git runs the hook from the top of the work tree
+	t.Chdir(repo)
+	runGit(t, repo, "add", "secret.env", filepath.Join("sub", "project", "main.go"))
+	t.Chdir(project)
+	if err := HookRun(); err != nil {
+		t.Fatalf("HookRun blocked files outside the links: %v", err)
+	}
+
+	for _, staged := range []string{"secret.env", filepath.Join("dir", "inner.txt"), ConfigFileName} {
+		runGit(t, project, "add", "-f", staged)
+		err := HookRun()
+		if err == nil || !strings.Contains(err.Error(), "commit blocked") {
+			t.Errorf("HookRun with %s staged = %v, want commit blocked", staged, err)
+		}
+		runGit(t, project, "rm", "-q", "--cached", staged)
+	}
+}
+
+func TestPreCommitHookScriptQuotesProjectDir(t *testing.T) {
+	script := preCommitHookScript("/tmp/it's here")
+	if !strings.Contains(script, `cd '/tmp/it'\''s here' ||`) {
+		t.Errorf("script does not quote the project directory:\n%s", script)
+	}
+	if !isLnkrHook([]byte(script)) {
+		t.Error("script is not recognized as an lnkr hook")
+	}
+}
+
+// runGit runs git in dir and fails the test on error
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, output)
+	}
+}
+
+// writeTestFile writes content to path and fails the test on error
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
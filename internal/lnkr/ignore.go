@@ -0,0 +1,297 @@
+package lnkr
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Ignore backend constants
+const (
+	IgnoreBackendGitExclude   = "git-exclude"
+	IgnoreBackendGitignore    = "gitignore"
+	IgnoreBackendHgignore     = "hgignore"
+	IgnoreBackendDockerignore = "dockerignore"
+	IgnoreBackendNone         = "none"
+)
+
+// IgnoreBackends lists the supported ignore backends
+var IgnoreBackends = []string{IgnoreBackendGitExclude, IgnoreBackendGitignore, IgnoreBackendHgignore, IgnoreBackendDockerignore, IgnoreBackendNone}
+
+// Ignore files written by the backends, relative to the project root
+const (
+	GitignoreFileName    = ".gitignore"
+	HgignoreFileName     = ".hgignore"
+	DockerignoreFileName = ".dockerignore"
+)
+
+// IgnoreWriter maintains the LNKR section of an ignore file. Paths are relative
+// to the project root; each backend turns them into its own pattern syntax.
+type IgnoreWriter interface {
+	// Path returns the ignore file, or an empty string when nothing is written
+	Path() string
+	// Pattern returns the escaped line written to the ignore file for a path
+	Pattern(path string) string
+	// ParsePattern returns the path a line of the LNKR section stands for
+	ParsePattern(pattern string) string
+	// ReadSection returns the patterns in the LNKR section and whether the section exists
+	ReadSection() ([]string, bool, error)
+	// WriteSection replaces the LNKR section with the given paths and moves it to
+	// the end of the file. With no paths, the section (including its markers) is removed.
+	WriteSection(paths []string) error
+}
+
+// isValidIgnoreBackend reports whether backend is a known ignore backend
+func isValidIgnoreBackend(backend string) bool {
+	return slices.Contains(IgnoreBackends, backend)
+}
+
+// GetIgnoreBackend returns the ignore backend from config or default value
+func (c *Config) GetIgnoreBackend() string {
+	if c.IgnoreBackend != "" {
+		return c.IgnoreBackend
+	}
+	return IgnoreBackendGitExclude
+}
+
+// GetIgnorePath returns the file the ignore backend writes to, or an empty string for none
+func (c *Config) GetIgnorePath() string {
+	writer, err := newIgnoreWriter(c)
+	if err != nil {
+		return ""
+	}
+	return writer.Path()
+}
+
+// newIgnoreWriter returns the ignore writer selected by the configuration
+func newIgnoreWriter(config *Config) (IgnoreWriter, error) {
+	switch config.GetIgnoreBackend() {
+	case IgnoreBackendGitExclude:
+		return &sectionIgnoreWriter{path: config.GetGitExcludePath(), anchor: "/", special: gitignoreSpecialChars, escapeTrailingSpaces: true}, nil
+	case IgnoreBackendGitignore:
+		return &sectionIgnoreWriter{path: projectFilePath(config, GitignoreFileName), anchor: "/", special: gitignoreSpecialChars, escapeTrailingSpaces: true}, nil
+	case IgnoreBackendHgignore:
+		return &sectionIgnoreWriter{path: projectFilePath(config, HgignoreFileName), anchor: hgignoreAnchor, special: hgignoreSpecialChars}, nil
+	case IgnoreBackendDockerignore:
+		return &sectionIgnoreWriter{path: projectFilePath(config, DockerignoreFileName), special: gitignoreSpecialChars}, nil
+	case IgnoreBackendNone:
+		return noneIgnoreWriter{}, nil
+	}
+	return nil, fmt.Errorf("invalid ignore backend: %s. Must be one of: %s", config.IgnoreBackend, strings.Join(IgnoreBackends, ", "))
+}
+
+// projectFilePath returns the path of a file at the project root
+func projectFilePath(config *Config, name string) string {
+	if config.Local == "" {
+		return name
+	}
+	return displayPath(filepath.Join(config.Local, name))
+}
+
+// displayPath returns path relative to the current directory when it is inside it
+func displayPath(path string) string {
+	currentDir, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(currentDir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(os.PathSeparator)) {
+		return path
+	}
+	return rel
+}
+
+// sectionIgnoreWriter keeps the LNKR section of a line-based ignore file
+type sectionIgnoreWriter struct {
+	path string
+	// Prefix anchoring patterns to the project root
+	anchor string
+	// Characters escaped with a backslash wherever they appear in a path
+	special string
+	// Whether trailing spaces can be kept by escaping them (they are trimmed otherwise)
+	escapeTrailingSpaces bool
+}
+
+func (w *sectionIgnoreWriter) Path() string {
+	return w.path
+}
+
+func (w *sectionIgnoreWriter) Pattern(path string) string {
+	return w.anchor + escapeIgnorePattern(strings.TrimPrefix(filepath.ToSlash(path), "/"), w.special, w.escapeTrailingSpaces)
+}
+
+func (w *sectionIgnoreWriter) ReadSection() ([]string, bool, error) {
+	content, err := os.ReadFile(w.path)
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	lines := strings.Split(string(content), "\n")
+	sectionStart, sectionEnd := findIgnoreSection(lines)
+	if sectionStart == -1 {
+		return nil, false, nil
+	}
+
+	var patterns []string
+	for i := sectionStart + 1; i < sectionEnd; i++ {
+		// Leading spaces are never written, and escaped trailing spaces are significant
+		line := strings.TrimLeft(strings.TrimRight(lines[i], "\r"), " \t")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") || isHgSyntaxDirective(w, line) {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns, true, nil
+}
+
+func (w *sectionIgnoreWriter) ParsePattern(pattern string) string {
+	return filepath.FromSlash(unescapeIgnorePattern(strings.TrimPrefix(pattern, w.anchor)))
+}
+
+// isHgSyntaxDirective reports whether a line of an .hgignore section is the
+// "syntax: glob" directive that older versions wrote at the start of the section
+func isHgSyntaxDirective(w *sectionIgnoreWriter, line string) bool {
+	return w.anchor == hgignoreAnchor && strings.HasPrefix(strings.TrimSpace(line), "syntax:")
+}
+
+func (w *sectionIgnoreWriter) WriteSection(paths []string) error {
+	journalTouchExclude(w.path)
+
+	// Read existing content
+	content, err := os.ReadFile(w.path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if os.IsNotExist(err) && len(paths) == 0 {
+		return nil
+	}
+
+	// Remove existing section if it exists
+	var lines []string
+	if len(content) > 0 {
+		lines = strings.Split(string(content), "\n")
+	}
+	if sectionStart, sectionEnd := findIgnoreSection(lines); sectionStart != -1 {
+		lines = append(lines[:sectionStart], lines[sectionEnd+1:]...)
+	}
+
+	// An ignore file that only held the section is removed with it
+	if len(paths) == 0 && strings.TrimSpace(strings.Join(lines, "\n")) == "" {
+		return os.Remove(w.path)
+	}
+
+	// Add new section at the end
+	if len(paths) > 0 {
+		lines = append(lines, GitExcludeSectionStart)
+		for _, path := range paths {
+			lines = append(lines, w.Pattern(path))
+		}
+		lines = append(lines, GitExcludeSectionEnd)
+	}
+
+	// Create directory if it doesn't exist
+	if err := checkNotInsideGitFile(w.path); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(w.path), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(w.path, []byte(strings.Join(lines, "\n")), 0644)
+}
+
+// Characters with a special meaning in gitignore (and .dockerignore) patterns
+const gitignoreSpecialChars = `\*?[`
+
+// Characters with a special meaning in Mercurial glob patterns; # starts a comment anywhere
+const hgignoreSpecialChars = `\*?[{}#`
+
+// Prefix of .hgignore patterns matched as globs from the repository root; unlike
+// a "syntax: glob" directive it applies to a single line
+const hgignoreAnchor = "rootglob:"
+
+// escapeIgnorePattern escapes a slash-separated path so that it only matches itself.
+// A leading ! or # would negate the pattern or make it a comment, so it is escaped too.
+func escapeIgnorePattern(path, special string, escapeTrailingSpaces bool) string {
+	var b strings.Builder
+	for i, r := range path {
+		if strings.ContainsRune(special, r) || (i == 0 && (r == '!' || r == '#')) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+
+	pattern := b.String()
+	if !escapeTrailingSpaces {
+		return pattern
+	}
+	// Unescaped trailing spaces are ignored
+	trimmed := strings.TrimRight(pattern, " ")
+	return trimmed + strings.Repeat(`\ `, len(pattern)-len(trimmed))
+}
+
+// unescapeIgnorePattern reverses escapeIgnorePattern, dropping unescaped trailing spaces
+func unescapeIgnorePattern(pattern string) string {
+	var b strings.Builder
+	end := 0
+	escaped := false
+	for _, r := range pattern {
+		if r == '\\' && !escaped {
+			escaped = true
+			continue
+		}
+		b.WriteRune(r)
+		if escaped || r != ' ' {
+			end = b.Len()
+		}
+		escaped = false
+	}
+	return b.String()[:end]
+}
+
+// noneIgnoreWriter is used when no ignore file should be maintained
+type noneIgnoreWriter struct{}
+
+func (noneIgnoreWriter) Path() string                         { return "" }
+func (noneIgnoreWriter) Pattern(path string) string           { return path }
+func (noneIgnoreWriter) ParsePattern(pattern string) string   { return pattern }
+func (noneIgnoreWriter) ReadSection() ([]string, bool, error) { return nil, false, nil }
+func (noneIgnoreWriter) WriteSection(paths []string) error    { return nil }
+
+// findIgnoreSection returns the line indexes of the LNKR section markers, or -1 if not found
+func findIgnoreSection(lines []string) (int, int) {
+	sectionStart := -1
+	for i, line := range lines {
+		if strings.TrimSpace(line) == GitExcludeSectionStart {
+			sectionStart = i
+		}
+		if sectionStart != -1 && strings.TrimSpace(line) == GitExcludeSectionEnd {
+			return sectionStart, i
+		}
+	}
+	return -1, -1
+}
+
+// checkNotInsideGitFile refuses paths that go through a .git file (worktrees and
+// submodules), where creating directories would produce a bogus .git/info
+func checkNotInsideGitFile(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	for dir := filepath.Dir(abs); dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		if filepath.Base(dir) != ".git" {
+			continue
+		}
+		if info, err := os.Stat(dir); err == nil && !info.IsDir() {
+			return fmt.Errorf("%s is a gitfile (worktree or submodule), not a directory. Unset git_exclude_path to use the resolved git exclude file", dir)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package lnkr
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestEscapeIgnorePattern(t *testing.T) {
+	tests := []struct {
+		name                 string
+		path                 string
+		special              string
+		escapeTrailingSpaces bool
+		want                 string
+	}{
+		{"plain", "dir/file.txt", gitignoreSpecialChars, true, "dir/file.txt"},
+		{"star", "a*b", gitignoreSpecialChars, true, `a\*b`},
+		{"question mark", "a?b", gitignoreSpecialChars, true, `a\?b`},
+		{"bracket", "a[1]", gitignoreSpecialChars, true, `a\[1]`},
+		{"backslash", `a\b`, gitignoreSpecialChars, true, `a\\b`},
+		{"leading bang", "!important", gitignoreSpecialChars, true, `\!important`},
+		{"inner bang", "a!b", gitignoreSpecialChars, true, "a!b"},
+		{"leading hash", "#notes", gitignoreSpecialChars, true, `\#notes`},
+		{"trailing spaces", "name  ", gitignoreSpecialChars, true, `name\ \ `},
+		{"inner space", "a b", gitignoreSpecialChars, true, "a b"},
+		{"trailing spaces kept raw", "name ", gitignoreSpecialChars, false, "name "},
+		{"hg braces", "a{b}", hgignoreSpecialChars, false, `a\{b\}`},
+		{"hg inner hash", "a#b", hgignoreSpecialChars, false, `a\#b`},
+		{"hg leading hash", "#a", hgignoreSpecialChars, false, `\#a`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := escapeIgnorePattern(tt.path, tt.special, tt.escapeTrailingSpaces); got != tt.want {
+				t.Errorf("escapeIgnorePattern(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnescapeIgnorePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{"dir/file.txt", "dir/file.txt"},
+		{`a\*b`, "a*b"},
+		{`a\?b`, "a?b"},
+		{`a\[1]`, "a[1]"},
+		{`a\\b`, `a\b`},
+		{`\!important`, "!important"},
+		{`\#notes`, "#notes"},
+		{`name\ \ `, "name  "},
+		// Unescaped trailing spaces are not part of the pattern
+		{"name  ", "name"},
+		{`name\  `, "name "},
+		{`a\{b\}`, "a{b}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			if got := unescapeIgnorePattern(tt.pattern); got != tt.want {
+				t.Errorf("unescapeIgnorePattern(%q) = %q, want %q", tt.pattern, got, tt.want)
+			}
+		})
+	}
+}
+
+// Paths with characters that have a special meaning in ignore files
+var specialPaths = []string{
+	"plain.txt",
+	"dir/nested file.txt",
+	"a*b",
+	"a?b",
+	"a[1]",
+	`back\slash`,
+	"!important",
+	"#notes",
+	"dir/#inner",
+	"a{b}",
+	"a#b",
+	"trailing ",
+	"dir/trailing  ",
+}
+
+func TestParsePatternRoundTrip(t *testing.T) {
+	tests := []struct {
+		backend string
+		// Whether trailing spaces survive; the other backends cannot express them
+		keepsTrailingSpaces bool
+		prefix              string
+	}{
+		{IgnoreBackendGitExclude, true, "/"},
+		{IgnoreBackendGitignore, true, "/"},
+		{IgnoreBackendHgignore, false, hgignoreAnchor},
+		{IgnoreBackendDockerignore, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.backend, func(t *testing.T) {
+			writer := newTestIgnoreWriter(t, tt.backend)
+			for _, path := range specialPaths {
+				want := filepath.FromSlash(path)
+				if !tt.keepsTrailingSpaces {
+					want = filepath.FromSlash(strings.TrimRight(path, " "))
+				}
+
+				pattern := writer.Pattern(filepath.FromSlash(path))
+				if !strings.HasPrefix(pattern, tt.prefix) {
+					t.Errorf("Pattern(%q) = %q, want prefix %q", path, pattern, tt.prefix)
+				}
+				if got := writer.ParsePattern(pattern); got != want {
+					t.Errorf("ParsePattern(Pattern(%q)) = %q (pattern %q), want %q", path, got, pattern, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSectionRoundTrip(t *testing.T) {
+	for _, backend := range []string{IgnoreBackendGitExclude, IgnoreBackendGitignore, IgnoreBackendHgignore, IgnoreBackendDockerignore} {
+		t.Run(backend, func(t *testing.T) {
+			writer := newTestIgnoreWriter(t, backend)
+			var paths []string
+			for _, path := range specialPaths {
+				paths = append(paths, filepath.FromSlash(path))
+			}
+			if err := writer.WriteSection(paths); err != nil {
+				t.Fatalf("WriteSection: %v", err)
+			}
+
+			patterns, found, err := writer.ReadSection()
+			if err != nil || !found {
+				t.Fatalf("ReadSection = %v, %v", found, err)
+			}
+			want := make([]string, len(paths))
+			for i, path := range paths {
+				want[i] = writer.Pattern(path)
+			}
+			if !slices.Equal(patterns, want) {
+				t.Errorf("ReadSection = %q, want %q", patterns, want)
+			}
+		})
+	}
+}
+
+func TestHgignoreSectionWithSyntaxDirective(t *testing.T) {
+	writer := newTestIgnoreWriter(t, IgnoreBackendHgignore)
+	section := writer.(*sectionIgnoreWriter)
+	for _, tt := range []struct {
+		line string
+		want bool
+	}{
+		{"syntax: glob", true},
+		{"  syntax:glob", true},
+		{"rootglob:syntax.txt", false},
+		{"rootglob:a", false},
+	} {
+		if got := isHgSyntaxDirective(section, tt.line); got != tt.want {
+			t.Errorf("isHgSyntaxDirective(%q) = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
+
+// newTestIgnoreWriter returns the writer of a backend for a project in a temporary directory
+func newTestIgnoreWriter(t *testing.T, backend string) IgnoreWriter {
+	t.Helper()
+	dir := t.TempDir()
+	config := &Config{
+		Local:          dir,
+		IgnoreBackend:  backend,
+		GitExcludePath: filepath.Join(dir, "exclude"),
+	}
+	writer, err := newIgnoreWriter(config)
+	if err != nil {
+		t.Fatalf("newIgnoreWriter(%s): %v", backend, err)
+	}
+	return writer
+}
@@ -4,28 +4,116 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"sort"
 	"strings"
 
 	"github.com/BurntSushi/toml"
 )
 
 // Init performs the initialization tasks
-func Init(remote string, createRemote bool, gitExcludePath string) error {
-	if err := createLnkTomlWithRemote(remote, createRemote, gitExcludePath); err != nil {
+func Init(remote string, createRemote bool, gitExcludePath string, ignoreBackend string) error {
+	if ignoreBackend != "" && !isValidIgnoreBackend(ignoreBackend) {
+		return fmt.Errorf("invalid ignore backend: %s. Must be one of: %s", ignoreBackend, strings.Join(IgnoreBackends, ", "))
+	}
+
+	// Re-initializing may move the ignore file to another backend
+	var previous IgnoreWriter
+	if _, err := os.Stat(ConfigFileName); err == nil {
+		if before, err := loadConfig(); err == nil {
+			previous, _ = newIgnoreWriter(before)
+		}
+	}
+
+	if err := createLnkTomlWithRemote(remote, createRemote, gitExcludePath, ignoreBackend); err != nil {
 		return fmt.Errorf("failed to create %s: %w", ConfigFileName, err)
 	}
 
-	if err := addToGitExclude(); err != nil {
-		return fmt.Errorf("failed to add to %s: %w", GitExcludePath, err)
+	config, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	clearPreviousIgnoreFile(previous, config)
+	if _, err := syncIgnoreFile(config); err != nil {
+		return fmt.Errorf("failed to add to %s: %w", config.GetIgnorePath(), err)
+	}
+
+	if err := mirrorConfigToRemote(remote); err != nil {
+		fmt.Printf("Warning: failed to mirror %s to remote: %v\n", ConfigFileName, err)
 	}
 
 	fmt.Println("Project initialized successfully!")
 	return nil
 }
 
+// InitFromRemote restores .lnkr.toml from the copy mirrored in the remote directory
+// and creates the links from the remote side
+func InitFromRemote(remote string, gitExcludePath string, ignoreBackend string) error {
+	if remote == "" {
+		return fmt.Errorf("remote directory is not specified")
+	}
+	if ignoreBackend != "" && !isValidIgnoreBackend(ignoreBackend) {
+		return fmt.Errorf("invalid ignore backend: %s. Must be one of: %s", ignoreBackend, strings.Join(IgnoreBackends, ", "))
+	}
+
+	if _, err := os.Stat(ConfigFileName); err == nil {
+		return fmt.Errorf("%s already exists. Run 'lnkr link --from-remote' instead", ConfigFileName)
+	}
+
+	// Get current directory as absolute path for local
+	currentDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	if !filepath.IsAbs(remote) {
+		remote, err = filepath.Abs(remote)
+		if err != nil {
+			return fmt.Errorf("failed to convert remote to absolute path: %w", err)
+		}
+	}
+
+	remoteConfigPath := filepath.Join(remote, ConfigFileName)
+	content, err := os.ReadFile(remoteConfigPath)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("no %s found in remote directory: %s", ConfigFileName, remote)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", remoteConfigPath, err)
+	}
+
+	config, _, err := decodeConfig(content)
+	if err != nil {
+		return fmt.Errorf("failed to decode %s: %w", remoteConfigPath, err)
+	}
+
+	// The mirrored copy carries the paths of the machine it was saved on
+	config.Local = currentDir
+	config.Remote = remote
+	if gitExcludePath != "" {
+		config.GitExcludePath = gitExcludePath
+	}
+	if ignoreBackend != "" {
+		config.IgnoreBackend = ignoreBackend
+	}
+
+	if err := saveConfig(config); err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+	fmt.Printf("Restored %s from %s\n", ConfigFileName, remoteConfigPath)
+
+	if _, err := syncIgnoreFile(config); err != nil {
+		return fmt.Errorf("failed to add to %s: %w", config.GetIgnorePath(), err)
+	}
+
+	if err := CreateLinks(true, false); err != nil {
+		return err
+	}
+
+	fmt.Println("Project initialized from remote successfully!")
+	return nil
+}
+
 // createLnkTomlWithRemote creates the .lnkr.toml file with remote if it doesn't exist
-func createLnkTomlWithRemote(remote string, createRemote bool, gitExcludePath string) error {
+func createLnkTomlWithRemote(remote string, createRemote bool, gitExcludePath string, ignoreBackend string) error {
 	filename := ConfigFileName
 
 	// Get current directory as absolute path for local
@@ -46,6 +134,7 @@ func createLnkTomlWithRemote(remote string, createRemote bool, gitExcludePath st
 		info, err := os.Stat(remote)
 		if os.IsNotExist(err) {
 			if createRemote {
+				journalTouch(remote)
 				if err := os.MkdirAll(remote, 0755); err != nil {
 					return fmt.Errorf("failed to create remote directory: %w", err)
 				}
@@ -62,14 +151,19 @@ func createLnkTomlWithRemote(remote string, createRemote bool, gitExcludePath st
 	}
 
 	// Create .lnkr.toml file if it doesn't exist
+	journalTouchConfig(filename)
 	if _, err := os.Stat(filename); os.IsNotExist(err) {
 		// Create new configuration file
 		config := map[string]interface{}{
+			"version":          ConfigVersion,
 			"local":            currentDir,
 			"remote":           remote,
 			"git_exclude_path": gitExcludePath,
 			"links":            []map[string]string{},
 		}
+		if ignoreBackend != "" {
+			config["ignore_backend"] = ignoreBackend
+		}
 
 		file, err := os.Create(filename)
 		if err != nil {
@@ -90,20 +184,27 @@ func createLnkTomlWithRemote(remote string, createRemote bool, gitExcludePath st
 			return fmt.Errorf("failed to read configuration file: %w", err)
 		}
 
-		var config map[string]interface{}
+		// Older schema versions are migrated, so the file is written in the current one
+		config := &Config{Version: ConfigVersion}
+		fromVersion := ConfigVersion
 		if len(content) > 0 {
-			if _, err := toml.Decode(string(content), &config); err != nil {
+			if config, fromVersion, err = decodeConfig(content); err != nil {
 				return fmt.Errorf("failed to decode configuration: %w", err)
 			}
 		}
 
 		// Always update local and remote
-		config["local"] = currentDir
-		config["remote"] = remote
+		config.Local = currentDir
+		config.Remote = remote
 
 		// Set git_exclude_path if not already set
-		if _, exists := config["git_exclude_path"]; !exists {
-			config["git_exclude_path"] = gitExcludePath
+		if config.GitExcludePath == "" {
+			config.GitExcludePath = gitExcludePath
+		}
+
+		// An explicit ignore backend replaces the configured one
+		if ignoreBackend != "" {
+			config.IgnoreBackend = ignoreBackend
 		}
 
 		file, err := os.Create(filename)
@@ -117,117 +218,10 @@ func createLnkTomlWithRemote(remote string, createRemote bool, gitExcludePath st
 			return fmt.Errorf("failed to encode configuration: %w", err)
 		}
 
-		fmt.Printf("Updated local and remote in %s\n", filename)
-	}
-	return nil
-}
-
-// addToGitExclude adds .lnkr.toml to .git/info/exclude
-func addToGitExclude() error {
-	return addToGitExcludeWithSection(ConfigFileName)
-}
-
-// addToGitExcludeWithSection adds entries to .git/info/exclude with section markers
-func addToGitExcludeWithSection(entry string) error {
-	return addMultipleToGitExclude([]string{entry})
-}
-
-// addMultipleToGitExclude adds multiple entries to .git/info/exclude with section markers
-func addMultipleToGitExclude(entries []string) error {
-	// Load config to get git exclude path
-	config, err := loadConfig()
-	if err != nil {
-		return fmt.Errorf("failed to load configuration: %w", err)
-	}
-
-	excludePath := config.GetGitExcludePath()
-	excludeDir := filepath.Dir(excludePath)
-
-	// Create directory if it doesn't exist
-	if err := os.MkdirAll(excludeDir, 0755); err != nil {
-		return err
-	}
-
-	// Read existing content
-	content, err := os.ReadFile(excludePath)
-	if err != nil && !os.IsNotExist(err) {
-		return err
-	}
-
-	// Check if section already exists
-	lines := strings.Split(string(content), "\n")
-	sectionStart := -1
-	sectionEnd := -1
-	sectionMarker := GitExcludeSectionStart
-	endMarker := GitExcludeSectionEnd
-
-	for i, line := range lines {
-		if strings.TrimSpace(line) == sectionMarker {
-			sectionStart = i
-		}
-		if sectionStart != -1 && strings.TrimSpace(line) == endMarker {
-			sectionEnd = i
-			break
-		}
-	}
-
-	// Collect existing entries from the section
-	existingEntries := make(map[string]struct{})
-	if sectionStart != -1 && sectionEnd != -1 {
-		for i := sectionStart + 1; i < sectionEnd; i++ {
-			line := strings.TrimSpace(lines[i])
-			if line != "" && !strings.HasPrefix(line, "#") {
-				// Add / prefix if not already present
-				if !strings.HasPrefix(line, "/") {
-					line = "/" + line
-				}
-				existingEntries[line] = struct{}{}
-			}
-		}
-	}
-
-	// Add new entries to existing ones
-	for _, entry := range entries {
-		// Add / prefix if not already present
-		if !strings.HasPrefix(entry, "/") {
-			entry = "/" + entry
+		if fromVersion < ConfigVersion {
+			fmt.Printf("Migrated %s from schema version %d to %d\n", filename, fromVersion, ConfigVersion)
 		}
-		existingEntries[entry] = struct{}{}
-	}
-
-	// Convert back to slice and sort
-	var allEntries []string
-	for entry := range existingEntries {
-		allEntries = append(allEntries, entry)
-	}
-	sort.Strings(allEntries)
-
-	// Remove existing section if it exists
-	if sectionStart != -1 && sectionEnd != -1 {
-		lines = append(lines[:sectionStart], lines[sectionEnd+1:]...)
-	}
-
-	// Add new section at the end
-	lines = append(lines, GitExcludeSectionStart)
-	lines = append(lines, allEntries...)
-	lines = append(lines, GitExcludeSectionEnd)
-
-	// Write back to file
-	file, err := os.Create(excludePath)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	_, err = file.WriteString(strings.Join(lines, "\n"))
-	if err != nil {
-		return err
-	}
-
-	if len(entries) == 1 {
-		fmt.Printf("Added %s to %s\n", entries[0], excludePath)
-	} else {
-		fmt.Printf("Added %d entries to %s\n", len(entries), excludePath)
+		fmt.Printf("Updated local and remote in %s\n", filename)
 	}
 	return nil
 }
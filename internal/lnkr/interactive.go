@@ -0,0 +1,137 @@
+package lnkr
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// AddInteractive lists the candidate files, lets the user pick them with a link
+// type per file, and adds the selection like Add
+func AddInteractive(opts AddOptions) error {
+	if !isValidLinkType(opts.LinkType) {
+		return fmt.Errorf("invalid link type: %s. Must be one of: %s", opts.LinkType, strings.Join(LinkTypes, ", "))
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	userConfig, err := LoadUserConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load user configuration: %w", err)
+	}
+
+	var candidates []string
+	var title string
+	if opts.FromRemote {
+		if config.Remote == "" {
+			return fmt.Errorf("remote directory not configured. Run 'lnkr init --remote <path>' first")
+		}
+		candidates, err = remoteOnlyCandidates(config, userConfig.Ignore)
+		title = "Select files only in " + config.Remote
+	} else {
+		if config.Local == "" {
+			return fmt.Errorf("local directory not configured. Run 'lnkr init --local <path>' first")
+		}
+		candidates, err = untrackedCandidates(config, userConfig.Ignore)
+		title = "Select untracked files in " + config.Local
+	}
+	if err != nil {
+		return fmt.Errorf("failed to list candidate files: %w", err)
+	}
+	if len(candidates) == 0 {
+		fmt.Println("No candidate files found.")
+		return nil
+	}
+
+	items := make([]pickerItem, len(candidates))
+	for i, c := range candidates {
+		items[i] = pickerItem{Path: c, Type: opts.LinkType}
+	}
+
+	selected, err := runPicker(title, items)
+	if err != nil {
+		return err
+	}
+	if selected == nil {
+		fmt.Println("Cancelled.")
+		return nil
+	}
+	if len(selected) == 0 {
+		fmt.Println("No new paths to add.")
+		return nil
+	}
+
+	links := make([]Link, len(selected))
+	for i, item := range selected {
+		links[i] = Link{Path: item.Path, Type: item.Type}
+	}
+	return addLinks(config, links, opts)
+}
+
+// untrackedCandidates returns the files under the local directory that git does
+// not track and lnkr does not manage yet
+func untrackedCandidates(config *Config, ignore []string) ([]string, error) {
+	index, err := readGitIndex(config.Local)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read git index: %w", err)
+	}
+
+	absRemote, _ := filepath.Abs(config.Remote)
+	return walkCandidates(config.Local, config, ignore, func(p, rel string, isDir bool) bool {
+		if isDir {
+			// The remote may live inside the project
+			return config.Remote == "" || p != absRemote
+		}
+		return index == nil || len(index.tracked(p)) == 0
+	})
+}
+
+// remoteOnlyCandidates returns the files under the remote directory that do not
+// exist locally and lnkr does not manage yet
+func remoteOnlyCandidates(config *Config, ignore []string) ([]string, error) {
+	return walkCandidates(config.Remote, config, ignore, func(p, rel string, isDir bool) bool {
+		if isDir {
+			return true
+		}
+		_, err := os.Lstat(filepath.Join(config.Local, rel))
+		return os.IsNotExist(err)
+	})
+}
+
+// walkCandidates returns the regular files under root, relative to root, that are
+// neither ignored nor managed and are accepted by keep. Directories rejected by
+// keep are skipped entirely.
+func walkCandidates(root string, config *Config, ignore []string, keep func(p, rel string, isDir bool) bool) ([]string, error) {
+	var candidates []string
+	err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == root {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+
+		skip := info.Name() == ".git" || rel == ConfigFileName || rel == HistoryDirName || isIgnored(rel, ignore) || isManagedPath(config, rel)
+		if info.IsDir() {
+			if skip || !keep(p, rel, true) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		// Symbolic links and special files cannot be linked
+		if skip || !info.Mode().IsRegular() || !keep(p, rel, false) {
+			return nil
+		}
+		candidates = append(candidates, rel)
+		return nil
+	})
+	return candidates, err
+}
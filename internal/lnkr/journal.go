@@ -0,0 +1,735 @@
+package lnkr
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Directory of the journal inside the git directory, or inside the user state
+// directory outside git
+const journalDirName = "lnkr"
+
+// Journal file and the number of entries kept in it
+const (
+	journalFileName   = "journal.jsonl"
+	journalObjectsDir = "objects"
+	journalMaxEntries = 50
+)
+
+// Kinds of paths recorded in the journal
+const (
+	pathKindFile    = "file"
+	pathKindSymlink = "symlink"
+	pathKindDir     = "dir"
+)
+
+// pathState is the state of a path before or after a command. A nil state means
+// that the path does not exist.
+type pathState struct {
+	Kind   string `json:"kind"`
+	Mode   string `json:"mode,omitempty"`
+	Hash   string `json:"hash,omitempty"`
+	Target string `json:"target,omitempty"`
+	// Another path the file shared its content with through a hard link
+	LinkedTo string `json:"linked_to,omitempty"`
+	// Encrypted object holding the content of a decrypted file, whose plain text
+	// is never stored. Hash is the hash of the plain text.
+	Object string `json:"object,omitempty"`
+
+	info os.FileInfo
+}
+
+// journalChange is a path changed by a command
+type journalChange struct {
+	Path   string     `json:"path"`
+	Before *pathState `json:"before,omitempty"`
+	After  *pathState `json:"after,omitempty"`
+}
+
+// journalEntry records what a mutating command changed
+type journalEntry struct {
+	Time    time.Time       `json:"time"`
+	Command string          `json:"command"`
+	Dir     string          `json:"dir"`
+	Config  []journalChange `json:"config,omitempty"`
+	Exclude []journalChange `json:"exclude,omitempty"`
+	Ops     []journalChange `json:"ops,omitempty"`
+	// Paths removed from the git index, which undo cannot put back
+	Untracked []string `json:"untracked,omitempty"`
+}
+
+// changes returns every change of the entry
+func (e *journalEntry) changes() []journalChange {
+	return slices.Concat(e.Config, e.Exclude, e.Ops)
+}
+
+// Categories of the recorded paths
+const (
+	journalConfig  = "config"
+	journalExclude = "exclude"
+)
+
+// journalRecorder keeps the state of the paths a command touches, taken just
+// before the command changes them. The content of recorded files goes straight
+// to the journal objects, so only hashes are kept in memory.
+type journalRecorder struct {
+	roots      []string
+	before     map[string]*pathState
+	categories map[string]string
+	untracked  []string
+	// Decrypted local files of encrypted links
+	encrypted  map[string]struct{}
+	journalDir string
+	err        error
+}
+
+// Recorder of the running command, nil when the command is not journaled
+var journal *journalRecorder
+
+// UndoOptions controls the behavior of Undo
+type UndoOptions struct {
+	// Undo even if the paths changed after the command
+	Force bool
+	// Only print what would be restored
+	DryRun bool
+}
+
+// Journaled runs a mutating command and appends what it changed to the journal,
+// so that 'lnkr undo' can revert it. Changes are recorded even when the command
+// fails halfway.
+func Journaled(command string, run func() error) error {
+	journal = &journalRecorder{
+		before:     make(map[string]*pathState),
+		categories: make(map[string]string),
+		encrypted:  make(map[string]struct{}),
+	}
+
+	err := run()
+
+	recorder := journal
+	journal = nil
+	if recorder.err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: the command was not journaled: %v\n", recorder.err)
+		return err
+	}
+	if journalErr := recorder.save(command); journalErr != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to write the journal: %v\n", journalErr)
+	}
+	return err
+}
+
+// journalTouch records the state of paths, and of everything under them, before
+// the running command changes them
+func journalTouch(paths ...string) {
+	if journal == nil {
+		return
+	}
+	for _, path := range paths {
+		journal.record(path, "")
+	}
+}
+
+// journalTouchConfig records a copy of .lnkr.toml before it is written
+func journalTouchConfig(path string) {
+	if journal != nil {
+		journal.record(path, journalConfig)
+	}
+}
+
+// journalTouchExclude records an ignore file before it is written
+func journalTouchExclude(path string) {
+	if journal != nil {
+		journal.record(path, journalExclude)
+	}
+}
+
+// journalUntracked records paths, relative to dir, that the running command
+// removed from the git index
+func journalUntracked(dir string, paths []string) {
+	if journal == nil {
+		return
+	}
+	for _, p := range paths {
+		abs, err := filepath.Abs(filepath.Join(dir, p))
+		if err != nil {
+			abs = filepath.Join(dir, p)
+		}
+		journal.untracked = append(journal.untracked, abs)
+	}
+}
+
+// journalTouchLink records the local path of a link before it is replaced or
+// removed. The remote file of a hard link is recorded too, so that undo can link
+// them again instead of restoring an independent copy.
+func journalTouchLink(link Link, localAbs, remoteAbs string) {
+	if link.Type == LinkTypeEncrypted {
+		journalTouchDecrypted(localAbs)
+		return
+	}
+	journalTouch(localAbs)
+	if link.Type == LinkTypeHard {
+		journalTouch(remoteAbs)
+	}
+}
+
+// journalTouchDecrypted records the decrypted local file of an encrypted link
+// before it is written or removed. Its content is stored encrypted.
+func journalTouchDecrypted(localAbs string) {
+	if journal == nil {
+		return
+	}
+	if abs, err := filepath.Abs(localAbs); err == nil {
+		journal.encrypted[abs] = struct{}{}
+	}
+	journal.record(localAbs, "")
+}
+
+// record takes the state of path, and of the directories that would be created
+// for it, unless they are already recorded
+func (r *journalRecorder) record(path, category string) {
+	if r.err != nil {
+		return
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		r.err = err
+		return
+	}
+	if category != "" {
+		r.categories[path] = category
+	}
+	if _, ok := r.before[path]; ok {
+		return
+	}
+
+	if r.journalDir == "" {
+		if r.journalDir, err = resolveJournalDir(); err != nil {
+			r.err = err
+			return
+		}
+	}
+
+	// Start from the topmost missing directory, so created parents are recorded too
+	root := path
+	for {
+		parent := filepath.Dir(root)
+		if parent == root {
+			break
+		}
+		if _, err := os.Lstat(parent); !os.IsNotExist(err) {
+			break
+		}
+		root = parent
+	}
+
+	states, err := captureTree(root, r.store)
+	if err != nil {
+		r.err = err
+		return
+	}
+	for p, state := range states {
+		// States taken earlier are from before the command
+		if _, ok := r.before[p]; !ok {
+			r.before[p] = state
+		}
+	}
+	r.roots = append(r.roots, root)
+}
+
+// save compares the recorded paths with their current state and appends the
+// changes to the journal
+func (r *journalRecorder) save(command string) error {
+	// Nothing was touched, so nothing was stored either
+	if len(r.roots) == 0 && len(r.untracked) == 0 {
+		return nil
+	}
+	if r.journalDir == "" {
+		journalDir, err := resolveJournalDir()
+		if err != nil {
+			return err
+		}
+		r.journalDir = journalDir
+	}
+
+	current := make(map[string]*pathState)
+	for _, root := range r.roots {
+		states, err := captureTree(root, nil)
+		if err != nil {
+			return err
+		}
+		maps.Copy(current, states)
+	}
+
+	paths := make([]string, 0, len(r.before))
+	for p := range r.before {
+		paths = append(paths, p)
+	}
+	for p := range current {
+		if _, ok := r.before[p]; !ok {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	entry := &journalEntry{Time: time.Now().UTC(), Command: command, Dir: dir, Untracked: r.untracked}
+	for _, p := range paths {
+		before, after := r.before[p], current[p]
+		if sameState(before, after, true) {
+			continue
+		}
+		if before != nil && before.Kind == pathKindFile {
+			before.LinkedTo = r.linkedTo(p, current)
+		}
+
+		change := journalChange{Path: p, Before: before, After: after}
+		switch r.categories[p] {
+		case journalConfig:
+			entry.Config = append(entry.Config, change)
+		case journalExclude:
+			entry.Exclude = append(entry.Exclude, change)
+		default:
+			entry.Ops = append(entry.Ops, change)
+		}
+	}
+	entries, err := loadJournal(r.journalDir)
+	if err != nil {
+		return err
+	}
+	// Without changes, the journal is only rewritten to drop the stored content
+	if len(entry.Config)+len(entry.Exclude)+len(entry.Ops)+len(entry.Untracked) > 0 {
+		entries = append(entries, entry)
+	}
+	if len(entries) > journalMaxEntries {
+		entries = entries[len(entries)-journalMaxEntries:]
+	}
+	return writeJournal(r.journalDir, entries)
+}
+
+// linkedTo returns the path a changed file is restored from as a hard link: the
+// first recorded path sharing its content that the command did not change, or
+// else the first one of the group. It returns an empty string when the file is
+// restored from its content.
+func (r *journalRecorder) linkedTo(path string, after map[string]*pathState) string {
+	before := r.before[path]
+	var group []string
+	for p, state := range r.before {
+		if state != nil && state.Kind == pathKindFile && os.SameFile(state.info, before.info) {
+			group = append(group, p)
+		}
+	}
+	if len(group) < 2 {
+		return ""
+	}
+	sort.Strings(group)
+
+	canonical := group[0]
+	for _, p := range group {
+		if sameState(r.before[p], after[p], true) {
+			canonical = p
+			break
+		}
+	}
+	if canonical == path {
+		return ""
+	}
+	return canonical
+}
+
+// store saves the content of a recorded file in the journal objects. The content
+// of decrypted files is encrypted first.
+func (r *journalRecorder) store(path string, state *pathState, content []byte) error {
+	objectsDir := filepath.Join(r.journalDir, journalObjectsDir)
+	if _, ok := r.encrypted[path]; !ok {
+		_, err := storeObject(objectsDir, content)
+		return err
+	}
+	blob, err := encryptContent(content)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt %s: %w", path, err)
+	}
+	state.Object, err = storeObject(objectsDir, blob)
+	return err
+}
+
+// captureTree returns the state of root and, for a directory, of everything under
+// it without following symbolic links. The content of files is passed to store
+// unless it is nil.
+func captureTree(root string, store func(path string, state *pathState, content []byte) error) (map[string]*pathState, error) {
+	states := make(map[string]*pathState)
+	if _, err := os.Lstat(root); os.IsNotExist(err) {
+		states[root] = nil
+		return states, nil
+	}
+
+	err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && info.Name() == HistoryDirName {
+			return filepath.SkipDir
+		}
+		state, err := capturePath(p, info, store)
+		if err != nil {
+			return err
+		}
+		if state != nil {
+			states[p] = state
+		}
+		return nil
+	})
+	return states, err
+}
+
+// capturePath returns the state of a path, or nil for special files. The content
+// of a file is passed to store unless it is nil.
+func capturePath(path string, info os.FileInfo, store func(path string, state *pathState, content []byte) error) (*pathState, error) {
+	state := &pathState{Mode: fmt.Sprintf("%04o", info.Mode().Perm()), info: info}
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		target, err := os.Readlink(path)
+		if err != nil {
+			return nil, err
+		}
+		state.Kind = pathKindSymlink
+		state.Mode = ""
+		state.Target = target
+	case info.IsDir():
+		state.Kind = pathKindDir
+	case info.Mode().IsRegular():
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		state.Kind = pathKindFile
+		state.Hash = contentHash(content)
+		if store != nil {
+			if err := store(path, state, content); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, nil
+	}
+	return state, nil
+}
+
+// sameState reports whether two states of a path are the same. With identity,
+// files must also still be the same file, so that hard links that were broken
+// or created count as changes.
+func sameState(a, b *pathState, identity bool) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Kind != b.Kind || a.Mode != b.Mode || a.Hash != b.Hash || a.Target != b.Target {
+		return false
+	}
+	if identity && a.Kind == pathKindFile {
+		return os.SameFile(a.info, b.info)
+	}
+	return true
+}
+
+// Undo reverts the most recent journaled command run in the current directory.
+// It refuses when a path changed after the command, unless forced.
+func Undo(opts UndoOptions) error {
+	journalDir, err := resolveJournalDir()
+	if err != nil {
+		return err
+	}
+	entries, err := loadJournal(journalDir)
+	if err != nil {
+		return err
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	index := -1
+	for i, entry := range entries {
+		if entry.Dir == dir {
+			index = i
+		}
+	}
+	if index == -1 {
+		fmt.Println("Nothing to undo.")
+		return nil
+	}
+	entry := entries[index]
+	fmt.Printf("Undoing '%s' (%s)\n", entry.Command, entry.Time.Local().Format("2006-01-02 15:04:05"))
+
+	// Refuse to overwrite changes made after the command
+	changes := entry.changes()
+	var changed []string
+	for _, c := range changes {
+		current, err := currentState(c.Path)
+		if err != nil {
+			return err
+		}
+		if !sameState(current, c.After, false) {
+			changed = append(changed, c.Path)
+		}
+	}
+	if len(changed) > 0 {
+		for _, p := range changed {
+			fmt.Printf("Changed since: %s\n", p)
+		}
+		if !opts.Force {
+			return fmt.Errorf("refusing to undo: %d path(s) changed after '%s'. Use --force to undo anyway", len(changed), entry.Command)
+		}
+	}
+
+	if opts.DryRun {
+		for _, c := range changes {
+			if c.Before == nil {
+				fmt.Printf("Would remove %s\n", c.Path)
+			} else {
+				fmt.Printf("Would restore %s\n", c.Path)
+			}
+		}
+		warnUntracked(entry)
+		return nil
+	}
+
+	failed := revertChanges(changes, filepath.Join(journalDir, journalObjectsDir))
+	warnUntracked(entry)
+
+	entries = slices.Delete(entries, index, index+1)
+	if err := writeJournal(journalDir, entries); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to restore %d path(s)", failed)
+	}
+	fmt.Printf("Undo completed. (%d paths reverted)\n", len(changes))
+	return nil
+}
+
+// warnUntracked reports the paths a command removed from the git index, which
+// stay removed after undo
+func warnUntracked(entry *journalEntry) {
+	for _, p := range entry.Untracked {
+		fmt.Printf("Warning: %s was removed from the git index and cannot be put back by undo. Run 'git add -f -- %s' to track it again\n", p, p)
+	}
+}
+
+// revertChanges puts every path back in its state before the command and
+// returns the number of paths that could not be restored
+func revertChanges(changes []journalChange, objectsDir string) int {
+	failed := 0
+
+	// Remove the current paths, deepest first
+	sorted := slices.Clone(changes)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Path > sorted[j].Path })
+	for _, c := range sorted {
+		info, err := os.Lstat(c.Path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err == nil && info.IsDir() && c.Before != nil && c.Before.Kind == pathKindDir {
+			continue
+		}
+		if err == nil {
+			err = os.Remove(c.Path)
+		}
+		if err != nil {
+			fmt.Printf("Error removing %s: %v\n", c.Path, err)
+			failed++
+			continue
+		}
+		if c.Before == nil {
+			fmt.Printf("Removed %s\n", c.Path)
+		}
+	}
+
+	// Recreate the previous paths, parents first. Files are restored before the
+	// hard links to them.
+	slices.Reverse(sorted)
+	linked := func(c journalChange) bool { return c.Before != nil && c.Before.LinkedTo != "" }
+	sort.SliceStable(sorted, func(i, j int) bool { return !linked(sorted[i]) && linked(sorted[j]) })
+	for _, c := range sorted {
+		if c.Before == nil {
+			continue
+		}
+		if err := restorePath(c.Path, c.Before, objectsDir); err != nil {
+			fmt.Printf("Error restoring %s: %v\n", c.Path, err)
+			failed++
+			continue
+		}
+		fmt.Printf("Restored %s\n", c.Path)
+	}
+	return failed
+}
+
+// restorePath recreates a path in the given state
+func restorePath(path string, state *pathState, objectsDir string) error {
+	var mode os.FileMode
+	if state.Mode != "" {
+		m, err := parseLinkMode(state.Mode)
+		if err != nil {
+			return err
+		}
+		mode = m
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	switch state.Kind {
+	case pathKindDir:
+		if err := os.MkdirAll(path, mode); err != nil {
+			return err
+		}
+		return os.Chmod(path, mode)
+	case pathKindSymlink:
+		return os.Symlink(state.Target, path)
+	case pathKindFile:
+		// Share the content again with the file it was hard linked to
+		if state.LinkedTo != "" {
+			if linked, err := currentState(state.LinkedTo); err == nil && linked != nil && linked.Hash == state.Hash {
+				return os.Link(state.LinkedTo, path)
+			}
+		}
+		content, err := readJournalObject(objectsDir, state)
+		if err != nil {
+			return err
+		}
+		return writeFileAtomic(path, content, mode)
+	}
+	return fmt.Errorf("unknown path kind: %s", state.Kind)
+}
+
+// readJournalObject returns the content of a recorded file, decrypting it when
+// it was stored encrypted
+func readJournalObject(objectsDir string, state *pathState) ([]byte, error) {
+	if state.Object == "" {
+		return readObject(objectsDir, state.Hash)
+	}
+	blob, err := readObject(objectsDir, state.Object)
+	if err != nil {
+		return nil, err
+	}
+	content, err := decryptContent(blob)
+	if err != nil {
+		return nil, err
+	}
+	if contentHash(content) != state.Hash {
+		return nil, fmt.Errorf("corrupt object %s: content does not match its hash", state.Object)
+	}
+	return content, nil
+}
+
+// currentState returns the current state of a path
+func currentState(path string) (*pathState, error) {
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return capturePath(path, info, nil)
+}
+
+// resolveJournalDir returns the journal directory: lnkr/ in the git directory, or
+// a directory per project in the user state directory outside git
+func resolveJournalDir() (string, error) {
+	if dir, err := gitPath(".", journalDirName); err == nil {
+		return dir, nil
+	}
+
+	stateDir, err := UserStateDir()
+	if err != nil {
+		return "", err
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(stateDir, "journal", contentHash([]byte(dir))[:16]), nil
+}
+
+// loadJournal reads the journal entries, oldest first
+func loadJournal(journalDir string) ([]*journalEntry, error) {
+	content, err := os.ReadFile(filepath.Join(journalDir, journalFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*journalEntry
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 64*1024), len(content)+1)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		entry := &journalEntry{}
+		if err := json.Unmarshal([]byte(line), entry); err != nil {
+			return nil, fmt.Errorf("failed to parse the journal: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// writeJournal writes the journal entries and removes the objects no entry refers to
+func writeJournal(journalDir string, entries []*journalEntry) error {
+	var buf bytes.Buffer
+	referenced := make(map[string]struct{})
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+		for _, c := range entry.changes() {
+			if c.Before == nil {
+				continue
+			}
+			if c.Before.Object != "" {
+				referenced[c.Before.Object] = struct{}{}
+			} else if c.Before.Hash != "" {
+				referenced[c.Before.Hash] = struct{}{}
+			}
+		}
+	}
+
+	if err := os.MkdirAll(journalDir, 0700); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(filepath.Join(journalDir, journalFileName), buf.Bytes(), 0600); err != nil {
+		return err
+	}
+
+	objectsDir := filepath.Join(journalDir, journalObjectsDir)
+	filepath.Walk(objectsDir, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+		hash := filepath.Base(filepath.Dir(p)) + info.Name()
+		if _, ok := referenced[hash]; !ok {
+			os.Remove(p)
+			os.Remove(filepath.Dir(p))
+		}
+		return nil
+	})
+	return nil
+}
@@ -0,0 +1,223 @@
+package lnkr
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupJournalProject creates a project with a .git directory and an empty
+// remote, and moves into the project. It returns both directories.
+func setupJournalProject(t *testing.T, links string) (string, string) {
+	t.Helper()
+	root := t.TempDir()
+	local := filepath.Join(root, "local")
+	remote := filepath.Join(root, "remote")
+	for _, dir := range []string{filepath.Join(local, ".git", "info"), remote} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(root, "config"))
+	t.Setenv("XDG_STATE_HOME", filepath.Join(root, "state"))
+
+	writeTestFile(t, filepath.Join(local, ConfigFileName), `version = 1
+local = "`+local+`"
+remote = "`+remote+`"
+git_exclude_path = ".git/info/exclude"
+`+links)
+	t.Chdir(local)
+	return local, remote
+}
+
+// readTestFile returns the content of path and fails the test on error
+func readTestFile(t *testing.T, path string) []byte {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return content
+}
+
+func TestUndoAdd(t *testing.T) {
+	local, _ := setupJournalProject(t, "")
+	writeTestFile(t, filepath.Join(local, "app.env"), "KEY=value\n")
+	configBefore := readTestFile(t, filepath.Join(local, ConfigFileName))
+
+	err := Journaled("lnkr add app.env", func() error {
+		return Add([]string{"app.env"}, AddOptions{LinkType: LinkTypeHard})
+	})
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if !strings.Contains(string(readTestFile(t, filepath.Join(local, ".git", "info", "exclude"))), "app.env") {
+		t.Fatal("Add did not update the exclude file")
+	}
+
+	if err := Undo(UndoOptions{}); err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	if got := readTestFile(t, filepath.Join(local, ConfigFileName)); !bytes.Equal(got, configBefore) {
+		t.Errorf("%s after undo =\n%s\nwant\n%s", ConfigFileName, got, configBefore)
+	}
+	if _, err := os.Lstat(filepath.Join(local, ".git", "info", "exclude")); !os.IsNotExist(err) {
+		t.Errorf("exclude file created by add was not removed: %v", err)
+	}
+	if got := readTestFile(t, filepath.Join(local, "app.env")); string(got) != "KEY=value\n" {
+		t.Errorf("app.env after undo = %q", got)
+	}
+}
+
+func TestUndoRemoveUnlinkHardLink(t *testing.T) {
+	local, remote := setupJournalProject(t, `
+[[links]]
+path = "app.env"
+type = "hard"
+`)
+	localFile := filepath.Join(local, "app.env")
+	remoteFile := filepath.Join(remote, "app.env")
+	writeTestFile(t, remoteFile, "KEY=value\n")
+	if err := os.Link(remoteFile, localFile); err != nil {
+		t.Fatal(err)
+	}
+
+	err := Journaled("lnkr remove --unlink app.env", func() error {
+		return Remove([]string{"app.env"}, true)
+	})
+	if err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if _, err := os.Lstat(localFile); !os.IsNotExist(err) {
+		t.Fatalf("Remove --unlink kept the local file: %v", err)
+	}
+
+	if err := Undo(UndoOptions{}); err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	localInfo, err := os.Stat(localFile)
+	if err != nil {
+		t.Fatalf("local file not restored: %v", err)
+	}
+	remoteInfo, err := os.Stat(remoteFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !os.SameFile(localInfo, remoteInfo) {
+		t.Error("local file was restored as a copy instead of a hard link to the remote")
+	}
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(config.Links) != 1 || config.Links[0].Path != "app.env" {
+		t.Errorf("links after undo = %+v", config.Links)
+	}
+}
+
+func TestUndoRefusesChangedPaths(t *testing.T) {
+	local, _ := setupJournalProject(t, "")
+	writeTestFile(t, filepath.Join(local, "app.env"), "KEY=value\n")
+	configPath := filepath.Join(local, ConfigFileName)
+	configBefore := readTestFile(t, configPath)
+
+	err := Journaled("lnkr add app.env", func() error {
+		return Add([]string{"app.env"}, AddOptions{LinkType: LinkTypeHard})
+	})
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	edited := append(readTestFile(t, configPath), "# edited\n"...)
+	writeTestFile(t, configPath, string(edited))
+
+	err = Undo(UndoOptions{})
+	if err == nil || !strings.Contains(err.Error(), "refusing to undo") {
+		t.Fatalf("Undo after an edit = %v, want a refusal", err)
+	}
+	if got := readTestFile(t, configPath); !bytes.Equal(got, edited) {
+		t.Errorf("refused undo changed %s", ConfigFileName)
+	}
+
+	if err := Undo(UndoOptions{Force: true}); err != nil {
+		t.Fatalf("Undo --force: %v", err)
+	}
+	if got := readTestFile(t, configPath); !bytes.Equal(got, configBefore) {
+		t.Errorf("%s after forced undo =\n%s\nwant\n%s", ConfigFileName, got, configBefore)
+	}
+}
+
+func TestUndoDryRun(t *testing.T) {
+	local, _ := setupJournalProject(t, "")
+	writeTestFile(t, filepath.Join(local, "app.env"), "KEY=value\n")
+	configPath := filepath.Join(local, ConfigFileName)
+	configBefore := readTestFile(t, configPath)
+
+	err := Journaled("lnkr add app.env", func() error {
+		return Add([]string{"app.env"}, AddOptions{LinkType: LinkTypeHard})
+	})
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	configAfter := readTestFile(t, configPath)
+
+	if err := Undo(UndoOptions{DryRun: true}); err != nil {
+		t.Fatalf("Undo --dry-run: %v", err)
+	}
+	if got := readTestFile(t, configPath); !bytes.Equal(got, configAfter) {
+		t.Errorf("dry run changed %s", ConfigFileName)
+	}
+
+	// The entry is kept, so the command can still be undone
+	if err := Undo(UndoOptions{}); err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	if got := readTestFile(t, configPath); !bytes.Equal(got, configBefore) {
+		t.Errorf("%s after undo =\n%s\nwant\n%s", ConfigFileName, got, configBefore)
+	}
+}
+
+func TestUndoSyncFromRemoteEncrypted(t *testing.T) {
+	local, remote := setupJournalProject(t, `
+[[links]]
+path = "app.env"
+type = "encrypted"
+`)
+	t.Setenv(EnvKey, "passphrase")
+	passphrase = nil
+	t.Cleanup(func() { passphrase = nil })
+
+	blob, err := encryptContent([]byte("KEY=remote\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(remote, "app.env"), string(blob))
+	localFile := filepath.Join(local, "app.env")
+	writeTestFile(t, localFile, "KEY=local edit\n")
+
+	err = Journaled("lnkr sync --from-remote --force", func() error {
+		return Sync(nil, SyncOptions{FromRemote: true, Force: true})
+	})
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if got := readTestFile(t, localFile); string(got) != "KEY=remote\n" {
+		t.Fatalf("local file after sync = %q", got)
+	}
+
+	// The local edit is only kept encrypted
+	filepath.Walk(filepath.Join(local, ".git", journalDirName), func(p string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() && bytes.Contains(readTestFile(t, p), []byte("local edit")) {
+			t.Errorf("%s holds the decrypted content", p)
+		}
+		return nil
+	})
+
+	if err := Undo(UndoOptions{}); err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	if got := readTestFile(t, localFile); string(got) != "KEY=local edit\n" {
+		t.Errorf("local file after undo = %q", got)
+	}
+}
@@ -0,0 +1,153 @@
+package lnkr
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Remote layout constants
+const (
+	RemoteLayoutDepth     = "depth"
+	RemoteLayoutGitRemote = "git-remote"
+	RemoteLayoutPathHash  = "path-hash"
+	RemoteLayoutTemplate  = "template"
+)
+
+// RemoteLayouts lists the supported remote layouts
+var RemoteLayouts = []string{RemoteLayoutDepth, RemoteLayoutGitRemote, RemoteLayoutPathHash, RemoteLayoutTemplate}
+
+// Default template used by the template layout when none is configured
+const DefaultRemoteTemplate = "{host}/{owner}/{repo}"
+
+// Git remote used by the git-remote layout and the {host}/{owner}/{repo} placeholders
+const gitRemoteName = "origin"
+
+// Length of the hash used by the path-hash layout and the {hash} placeholder
+const pathHashLength = 12
+
+var templatePlaceholder = regexp.MustCompile(`\{([a-z]+)\}`)
+
+// Placeholders supported by remote templates
+var templatePlaceholders = []string{"host", "owner", "repo", "dir", "parent", "path", "hash"}
+
+// ResolveRemoteLayout returns the remote layout to use.
+// Precedence: flag > LNKR_REMOTE_LAYOUT > remote_layout in user config > depth
+func ResolveRemoteLayout(flag string, userConfig *UserConfig) (string, error) {
+	layout := RemoteLayoutDepth
+	switch {
+	case flag != "":
+		layout = flag
+	case os.Getenv(EnvRemoteLayout) != "":
+		layout = os.Getenv(EnvRemoteLayout)
+	case userConfig.RemoteLayout != "":
+		layout = userConfig.RemoteLayout
+	}
+
+	for _, l := range RemoteLayouts {
+		if l == layout {
+			return layout, nil
+		}
+	}
+	return "", fmt.Errorf("invalid remote layout: %s. Must be one of: %s", layout, strings.Join(RemoteLayouts, ", "))
+}
+
+// ResolveRemoteTemplate returns the template used by the template layout.
+// Precedence: flag > LNKR_REMOTE_TEMPLATE > remote_template in user config > DefaultRemoteTemplate
+func ResolveRemoteTemplate(flag string, userConfig *UserConfig) string {
+	switch {
+	case flag != "":
+		return flag
+	case os.Getenv(EnvRemoteTemplate) != "":
+		return os.Getenv(EnvRemoteTemplate)
+	case userConfig.RemoteTemplate != "":
+		return userConfig.RemoteTemplate
+	}
+	return DefaultRemoteTemplate
+}
+
+// GetRemotePath returns the remote path for currentDir under baseDir according to the layout
+func GetRemotePath(layout, currentDir, baseDir string, depth int, template string) (string, error) {
+	switch layout {
+	case RemoteLayoutDepth:
+		return GetDefaultRemotePath(currentDir, baseDir, depth), nil
+	case RemoteLayoutGitRemote:
+		return expandRemoteTemplate(DefaultRemoteTemplate, currentDir, baseDir)
+	case RemoteLayoutPathHash:
+		return expandRemoteTemplate("{dir}-{hash}", currentDir, baseDir)
+	case RemoteLayoutTemplate:
+		return expandRemoteTemplate(template, currentDir, baseDir)
+	default:
+		return "", fmt.Errorf("unknown remote layout: %s", layout)
+	}
+}
+
+// expandRemoteTemplate expands the placeholders of a remote path template:
+//
+//	{host}   host of the origin remote (e.g. github.com)
+//	{owner}  owner (and group path) of the origin remote
+//	{repo}   repository name of the origin remote
+//	{dir}    name of the current directory
+//	{parent} name of the parent directory
+//	{path}   absolute path of the current directory without the leading separator
+//	{hash}   short sha256 of the absolute path of the current directory
+func expandRemoteTemplate(template, currentDir, baseDir string) (string, error) {
+	if err := validateRemoteTemplate(template); err != nil {
+		return "", err
+	}
+
+	values := map[string]string{
+		"dir":    filepath.Base(currentDir),
+		"parent": filepath.Base(filepath.Dir(currentDir)),
+		"path":   strings.TrimPrefix(currentDir, string(os.PathSeparator)),
+	}
+	sum := sha256.Sum256([]byte(currentDir))
+	values["hash"] = hex.EncodeToString(sum[:])[:pathHashLength]
+
+	// Only read the git remote when the template needs it
+	if strings.Contains(template, "{host}") || strings.Contains(template, "{owner}") || strings.Contains(template, "{repo}") {
+		url, err := readGitRemoteURL(currentDir, gitRemoteName)
+		if err != nil {
+			return "", fmt.Errorf("failed to read git remote: %w", err)
+		}
+		host, owner, repo, err := parseGitRemoteURL(url)
+		if err != nil {
+			return "", err
+		}
+		values["host"], values["owner"], values["repo"] = host, owner, repo
+	}
+
+	expanded := templatePlaceholder.ReplaceAllStringFunc(template, func(m string) string {
+		return values[m[1:len(m)-1]]
+	})
+
+	expanded = filepath.Clean(filepath.FromSlash(expanded))
+	if filepath.IsAbs(expanded) || expanded == "." || expanded == ".." || strings.HasPrefix(expanded, ".."+string(os.PathSeparator)) {
+		return "", fmt.Errorf("remote template must expand to a relative path inside the remote root: %s", expanded)
+	}
+
+	return filepath.Join(baseDir, expanded), nil
+}
+
+// validateRemoteTemplate checks that a remote template is not empty and only uses known placeholders
+func validateRemoteTemplate(template string) error {
+	if template == "" {
+		return fmt.Errorf("remote template is empty")
+	}
+
+	var unknown []string
+	for _, m := range templatePlaceholder.FindAllStringSubmatch(template, -1) {
+		if !slices.Contains(templatePlaceholders, m[1]) {
+			unknown = append(unknown, m[0])
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown placeholder in remote template: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
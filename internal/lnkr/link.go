@@ -6,7 +6,9 @@ import (
 	"path/filepath"
 )
 
-func CreateLinks(fromRemote bool) error {
+// CreateLinks creates the configured links. Templates whose local file was edited
+// are only re-rendered with force.
+func CreateLinks(fromRemote, force bool) error {
 	config, err := loadConfig()
 	if err != nil {
 		return fmt.Errorf("failed to load configuration: %w", err)
@@ -18,18 +20,35 @@ func CreateLinks(fromRemote bool) error {
 	}
 
 	for _, link := range config.Links {
-		if err := createLinkWithBase(link, fromRemote, config); err != nil {
+		if err := createLinkWithBase(link, fromRemote, force, config); err != nil {
 			fmt.Printf("Error creating link for %s: %v\n", link.Path, err)
 			continue
 		}
 		// If err is nil, the link was either created successfully or skipped with a warning
+
+		// Enforce the mode and owner of the link
+		localAbs := filepath.Join(config.Local, link.Path)
+		if _, err := os.Stat(localAbs); err == nil {
+			if err := applyLinkOptions(link, localAbs); err != nil {
+				fmt.Printf("Error applying options to %s: %v\n", link.Path, err)
+			}
+		}
 	}
 
 	fmt.Println("Link creation completed.")
 	return nil
 }
 
-func createLinkWithBase(link Link, fromRemote bool, config *Config) error {
+func createLinkWithBase(link Link, fromRemote, force bool, config *Config) error {
+	// Templates are always rendered from the remote into the local path
+	if link.Template {
+		return renderLinkWithBase(link, force, config)
+	}
+	// Encrypted links are decrypted into the local path or encrypted into the remote
+	if link.Type == LinkTypeEncrypted {
+		return linkEncrypted(link, config)
+	}
+
 	// Determine source and target directories based on fromRemote flag
 	var sourceDir, targetDir string
 	if fromRemote {
@@ -57,6 +76,7 @@ func createLinkWithBase(link Link, fromRemote bool, config *Config) error {
 		fmt.Printf("Warning: target already exists: %s\n", targetAbs)
 		return nil // Skip this link instead of returning error
 	}
+	journalTouch(targetAbs)
 
 	switch link.Type {
 	case LinkTypeHard:
@@ -79,9 +99,56 @@ func createLinkWithBase(link Link, fromRemote bool, config *Config) error {
 			return fmt.Errorf("failed to create symbolic link: %w", err)
 		}
 		fmt.Printf("Created symbolic link: %s -> %s\n", sourceAbs, targetAbs)
+	case LinkTypeCopy:
+		// Create an independent copy (works for both files and directories)
+		targetParentDir := filepath.Dir(targetAbs)
+		if err := os.MkdirAll(targetParentDir, 0755); err != nil {
+			return fmt.Errorf("failed to create target directory: %w", err)
+		}
+		if err := copyPath(sourceAbs, targetAbs); err != nil {
+			return fmt.Errorf("failed to create copy: %w", err)
+		}
+		fmt.Printf("Created copy: %s -> %s\n", sourceAbs, targetAbs)
 	default:
 		return fmt.Errorf("unknown link type: %s", link.Type)
 	}
 
 	return nil
 }
+
+// renderLinkWithBase renders a template link from the remote into the local directory.
+// A local file that differs from the rendered template is kept unless force is set.
+func renderLinkWithBase(link Link, force bool, config *Config) error {
+	absRemote, err := filepath.Abs(config.Remote)
+	if err != nil {
+		return fmt.Errorf("invalid remote directory path: %w", err)
+	}
+	localAbs := filepath.Join(config.Local, link.Path)
+	remoteAbs := filepath.Join(absRemote, link.Path)
+
+	if _, err := os.Stat(remoteAbs); os.IsNotExist(err) {
+		return fmt.Errorf("template does not exist: %s", remoteAbs)
+	}
+
+	if _, err := os.Stat(localAbs); err == nil && !force {
+		same, err := sameAsRemote(link, localAbs, remoteAbs)
+		if err != nil {
+			return err
+		}
+		if !same {
+			fmt.Printf("Warning: %s differs from the rendered template. Check with 'lnkr diff %s' or use --force to re-render it\n", localAbs, link.Path)
+			return nil
+		}
+	}
+
+	written, err := renderLink(link, localAbs, remoteAbs)
+	if err != nil {
+		return err
+	}
+	if written {
+		fmt.Printf("Rendered template: %s -> %s\n", remoteAbs, localAbs)
+	} else {
+		fmt.Printf("Template is up to date: %s\n", localAbs)
+	}
+	return nil
+}
@@ -0,0 +1,277 @@
+package lnkr
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/template"
+)
+
+// validateLinkOptions checks the mode, owner and template settings of a link
+func validateLinkOptions(link Link) error {
+	if link.Mode != "" {
+		if _, err := parseLinkMode(link.Mode); err != nil {
+			return fmt.Errorf("invalid mode %q for %s: %w", link.Mode, link.Path, err)
+		}
+	}
+	if link.Owner != "" {
+		if _, _, err := parseLinkOwner(link.Owner); err != nil {
+			return fmt.Errorf("invalid owner %q for %s: %w", link.Owner, link.Path, err)
+		}
+	}
+	if link.Template && link.Type != LinkTypeCopy {
+		return fmt.Errorf("template requires link type %s for %s (got %s)", LinkTypeCopy, link.Path, link.Type)
+	}
+	return nil
+}
+
+// parseLinkMode parses an octal permission mode such as 0600
+func parseLinkMode(mode string) (os.FileMode, error) {
+	value, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil {
+		return 0, fmt.Errorf("must be an octal number such as 0600")
+	}
+	if value > 0777 {
+		return 0, fmt.Errorf("must be at most 0777")
+	}
+	return os.FileMode(value), nil
+}
+
+// parseLinkOwner splits an owner of the form user or user:group
+func parseLinkOwner(owner string) (string, string, error) {
+	name, group, hasGroup := strings.Cut(owner, ":")
+	if name == "" || (hasGroup && group == "") {
+		return "", "", fmt.Errorf("must be user or user:group")
+	}
+	return name, group, nil
+}
+
+// lookupLinkOwner resolves the owner of a link to a uid and gid.
+// The gid is -1 when no group is given.
+func lookupLinkOwner(owner string) (int, int, error) {
+	name, group, err := parseLinkOwner(owner)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	uid, err := strconv.Atoi(name)
+	if err != nil {
+		u, err := user.Lookup(name)
+		if err != nil {
+			return 0, 0, err
+		}
+		if uid, err = strconv.Atoi(u.Uid); err != nil {
+			return 0, 0, fmt.Errorf("unsupported uid %s for user %s", u.Uid, name)
+		}
+	}
+
+	gid := -1
+	if group != "" {
+		if gid, err = strconv.Atoi(group); err != nil {
+			g, err := user.LookupGroup(group)
+			if err != nil {
+				return 0, 0, err
+			}
+			if gid, err = strconv.Atoi(g.Gid); err != nil {
+				return 0, 0, fmt.Errorf("unsupported gid %s for group %s", g.Gid, group)
+			}
+		}
+	}
+	return uid, gid, nil
+}
+
+// applyLinkOptions enforces the mode and owner of a link on its local path.
+// Encrypted links default to 0600.
+// Symbolic links are followed, so the options apply to the file they point at.
+func applyLinkOptions(link Link, localAbs string) error {
+	// Record the path the options change, which is the target of a symbolic link
+	if checkLinkOptions(link, localAbs) != "" {
+		if link.Type == LinkTypeEncrypted {
+			journalTouchDecrypted(localAbs)
+		} else if target, err := filepath.EvalSymlinks(localAbs); err == nil {
+			journalTouch(target)
+		}
+	}
+	if linkMode(link) != "" {
+		mode, err := parseLinkMode(linkMode(link))
+		if err != nil {
+			return err
+		}
+		if err := os.Chmod(localAbs, mode); err != nil {
+			return fmt.Errorf("failed to set mode: %w", err)
+		}
+	}
+	if link.Owner != "" {
+		uid, gid, err := lookupLinkOwner(link.Owner)
+		if err != nil {
+			return fmt.Errorf("failed to look up owner %s: %w", link.Owner, err)
+		}
+		if err := os.Chown(localAbs, uid, gid); err != nil {
+			return fmt.Errorf("failed to set owner: %w", err)
+		}
+	}
+	return nil
+}
+
+// checkLinkOptions describes how the local path of a link differs from its mode
+// and owner settings, or returns an empty string when it matches
+func checkLinkOptions(link Link, localAbs string) string {
+	if linkMode(link) == "" && link.Owner == "" {
+		return ""
+	}
+
+	info, err := os.Stat(localAbs)
+	if err != nil {
+		return fmt.Sprintf("Cannot stat: %v", err)
+	}
+
+	if linkMode(link) != "" {
+		mode, err := parseLinkMode(linkMode(link))
+		if err != nil {
+			return fmt.Sprintf("Invalid mode: %s", link.Mode)
+		}
+		if info.Mode().Perm() != mode {
+			return fmt.Sprintf("Wrong mode: %04o (expected: %04o)", info.Mode().Perm(), mode)
+		}
+	}
+
+	if link.Owner != "" {
+		uid, gid, err := lookupLinkOwner(link.Owner)
+		if err != nil {
+			return fmt.Sprintf("Unknown owner: %s", link.Owner)
+		}
+		stat, ok := info.Sys().(*syscall.Stat_t)
+		if !ok {
+			return "Cannot determine owner"
+		}
+		if int(stat.Uid) != uid || (gid != -1 && int(stat.Gid) != gid) {
+			return fmt.Sprintf("Wrong owner: %d:%d (expected: %s)", stat.Uid, stat.Gid, link.Owner)
+		}
+	}
+	return ""
+}
+
+// templateData holds the project variables available to template links
+type templateData struct {
+	// Path of the link relative to the project root
+	Path string
+	// Local and remote directories of the project
+	Local  string
+	Remote string
+	// Name of the local directory
+	Project  string
+	Hostname string
+	User     string
+}
+
+// renderTemplate renders the remote file of a template link. localAbs and
+// remoteAbs are the local and remote paths of the link.
+func renderTemplate(link Link, localAbs, remoteAbs string) ([]byte, error) {
+	content, err := os.ReadFile(remoteAbs)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := template.New(link.Path).
+		Option("missingkey=error").
+		Funcs(template.FuncMap{"env": os.Getenv}).
+		Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	// Callers may pass the local path relative to the current directory
+	if localAbs, err = filepath.Abs(localAbs); err != nil {
+		return nil, err
+	}
+	if remoteAbs, err = filepath.Abs(remoteAbs); err != nil {
+		return nil, err
+	}
+	data := templateData{
+		Path:   link.Path,
+		Local:  linkRoot(localAbs, link.Path),
+		Remote: linkRoot(remoteAbs, link.Path),
+	}
+	data.Project = filepath.Base(data.Local)
+	data.Hostname, _ = os.Hostname()
+	if u, err := user.Current(); err == nil {
+		data.User = u.Username
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return nil, fmt.Errorf("failed to render template: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+// linkRoot returns the directory a link path was joined to
+func linkRoot(abs, linkPath string) string {
+	return strings.TrimSuffix(abs, string(os.PathSeparator)+linkPath)
+}
+
+// renderLink renders a template link into its local path, keeping the file
+// untouched when the rendered content has not changed. It reports whether the
+// file was written.
+func renderLink(link Link, localAbs, remoteAbs string) (bool, error) {
+	rendered, err := renderTemplate(link, localAbs, remoteAbs)
+	if err != nil {
+		return false, err
+	}
+
+	if current, err := os.ReadFile(localAbs); err == nil && bytes.Equal(current, rendered) {
+		return false, nil
+	}
+
+	journalTouch(localAbs)
+	if err := os.MkdirAll(filepath.Dir(localAbs), 0755); err != nil {
+		return false, fmt.Errorf("failed to create local directory: %w", err)
+	}
+
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(remoteAbs); err == nil {
+		mode = info.Mode().Perm()
+	}
+	return true, writeFileAtomic(localAbs, rendered, mode)
+}
+
+// sameAsRemote reports whether the local path of a copy holds the remote content,
+// the rendered remote content for a template link, or the decrypted remote
+// content for an encrypted link
+func sameAsRemote(link Link, localAbs, remoteAbs string) (bool, error) {
+	if !link.Template && link.Type != LinkTypeEncrypted {
+		return sameContent(localAbs, remoteAbs)
+	}
+
+	expected, err := expectedLocalContent(link, localAbs, remoteAbs)
+	if err != nil {
+		return false, err
+	}
+	current, err := os.ReadFile(localAbs)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(current, expected), nil
+}
+
+// expectedLocalContent returns the content the local path of a template or
+// encrypted link is generated from
+func expectedLocalContent(link Link, localAbs, remoteAbs string) ([]byte, error) {
+	if link.Type == LinkTypeEncrypted {
+		return decryptFile(remoteAbs)
+	}
+	return renderTemplate(link, localAbs, remoteAbs)
+}
+
+// linkMode returns the mode enforced on the local path of a link, if any
+func linkMode(link Link) string {
+	if link.Mode == "" && link.Type == LinkTypeEncrypted {
+		return encryptedLocalMode
+	}
+	return link.Mode
+}
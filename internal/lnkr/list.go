@@ -0,0 +1,237 @@
+package lnkr
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// List output format constants
+const (
+	ListFormatPlain = "plain"
+	ListFormatJSON  = "json"
+	ListFormatNull  = "null"
+)
+
+// ListFormats lists the supported list output formats
+var ListFormats = []string{ListFormatPlain, ListFormatJSON, ListFormatNull}
+
+// Source of truth constants
+const (
+	SourceShared   = "shared"
+	SourceLocal    = "local"
+	SourceRemote   = "remote"
+	SourceDiverged = "diverged"
+	SourceMissing  = "missing"
+)
+
+// ListOptions controls the output of List
+type ListOptions struct {
+	Tree   bool
+	Type   string
+	Format string
+}
+
+// ListEntry describes a configured link. Sizes are -1 when the path does not exist.
+type ListEntry struct {
+	Path       string `json:"path"`
+	Type       string `json:"type"`
+	Size       int64  `json:"size"`
+	RemoteSize int64  `json:"remote_size"`
+	Source     string `json:"source"`
+}
+
+// List prints the configured links with their sizes and source of truth
+func List(opts ListOptions) error {
+	if opts.Format == "" {
+		opts.Format = ListFormatPlain
+	}
+	if opts.Format != ListFormatPlain && opts.Format != ListFormatJSON && opts.Format != ListFormatNull {
+		return fmt.Errorf("invalid format: %s. Must be one of: %s", opts.Format, strings.Join(ListFormats, ", "))
+	}
+	if opts.Type != "" && !isValidLinkType(opts.Type) {
+		return fmt.Errorf("invalid link type: %s. Must be one of: %s", opts.Type, strings.Join(LinkTypes, ", "))
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	entries := []ListEntry{}
+	for _, link := range config.Links {
+		if opts.Type != "" && link.Type != opts.Type {
+			continue
+		}
+		entries = append(entries, newListEntry(link, config))
+	}
+
+	switch opts.Format {
+	case ListFormatJSON:
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(entries)
+	case ListFormatNull:
+		for _, e := range entries {
+			fmt.Printf("%s\x00", e.Path)
+		}
+		return nil
+	}
+
+	if len(entries) == 0 {
+		fmt.Printf("No links found in %s\n", ConfigFileName)
+		return nil
+	}
+
+	if opts.Tree {
+		printListTree(entries)
+	} else {
+		printListTable(entries)
+	}
+	return nil
+}
+
+func newListEntry(link Link, config *Config) ListEntry {
+	entry := ListEntry{
+		Path:       link.Path,
+		Type:       link.Type,
+		Size:       -1,
+		RemoteSize: -1,
+	}
+
+	localAbs := filepath.Join(config.Local, link.Path)
+	remoteAbs := filepath.Join(config.Remote, link.Path)
+
+	localInfo, localErr := os.Lstat(localAbs)
+	remoteInfo, remoteErr := os.Lstat(remoteAbs)
+	if localErr == nil {
+		entry.Size = pathSize(localAbs)
+	}
+	if remoteErr == nil {
+		entry.RemoteSize = pathSize(remoteAbs)
+	}
+	entry.Source = sourceOfTruth(link, localAbs, remoteAbs, localInfo, remoteInfo)
+
+	return entry
+}
+
+// sourceOfTruth reports which side holds the authoritative content of a link
+func sourceOfTruth(link Link, localAbs, remoteAbs string, localInfo, remoteInfo os.FileInfo) string {
+	switch {
+	case localInfo == nil && remoteInfo == nil:
+		return SourceMissing
+	case remoteInfo == nil:
+		return SourceLocal
+	case localInfo == nil:
+		return SourceRemote
+	}
+
+	// A symbolic link defers to the side it points at
+	if localInfo.Mode()&os.ModeSymlink != 0 {
+		return SourceRemote
+	}
+	if remoteInfo.Mode()&os.ModeSymlink != 0 {
+		return SourceLocal
+	}
+
+	switch link.Type {
+	case LinkTypeHard:
+		if os.SameFile(localInfo, remoteInfo) {
+			return SourceShared
+		}
+	case LinkTypeCopy, LinkTypeEncrypted:
+		if same, err := sameAsRemote(link, localAbs, remoteAbs); err == nil && same {
+			return SourceShared
+		}
+		if localInfo.ModTime().After(remoteInfo.ModTime()) {
+			return SourceLocal
+		}
+		return SourceRemote
+	}
+	return SourceDiverged
+}
+
+// pathSize returns the size of a file, or the total size of the files in a directory
+func pathSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return -1
+	}
+	if !info.IsDir() {
+		return info.Size()
+	}
+
+	var total int64
+	filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total
+}
+
+// formatSize formats a size in bytes for display
+func formatSize(size int64) string {
+	if size < 0 {
+		return "-"
+	}
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%dB", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%c", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+func printListTable(entries []ListEntry) {
+	// Calculate max width for each column
+	maxPath := len("Path")
+	maxType := len("Type")
+	maxSize := len("Size")
+	maxRemoteSize := len("Remote Size")
+	for _, e := range entries {
+		maxPath = max(maxPath, len(e.Path))
+		maxType = max(maxType, len(e.Type))
+		maxSize = max(maxSize, len(formatSize(e.Size)))
+		maxRemoteSize = max(maxRemoteSize, len(formatSize(e.RemoteSize)))
+	}
+
+	// Print header
+	header := fmt.Sprintf("%-*s  %-*s  %*s  %*s  %s", maxPath, "Path", maxType, "Type", maxSize, "Size", maxRemoteSize, "Remote Size", "Source")
+	fmt.Println(header)
+	fmt.Println(strings.Repeat("-", len(header)))
+
+	for _, e := range entries {
+		fmt.Printf("%-*s  %-*s  %*s  %*s  %s\n", maxPath, e.Path, maxType, e.Type, maxSize, formatSize(e.Size), maxRemoteSize, formatSize(e.RemoteSize), e.Source)
+	}
+}
+
+func printListTree(entries []ListEntry) {
+	// Entries are sorted by path, so directories can be printed as they change
+	var printed []string
+	for _, e := range entries {
+		dirs := strings.Split(filepath.Dir(e.Path), string(os.PathSeparator))
+		if dirs[0] == "." {
+			dirs = nil
+		}
+
+		// Keep the directories shared with the previous entry
+		common := 0
+		for common < len(printed) && common < len(dirs) && printed[common] == dirs[common] {
+			common++
+		}
+		for i := common; i < len(dirs); i++ {
+			fmt.Printf("%s%s/\n", strings.Repeat("  ", i), dirs[i])
+		}
+		printed = dirs
+
+		fmt.Printf("%s%s  [%s, %s / %s, %s]\n", strings.Repeat("  ", len(dirs)), filepath.Base(e.Path), e.Type, formatSize(e.Size), formatSize(e.RemoteSize), e.Source)
+	}
+}
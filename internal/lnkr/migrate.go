@@ -0,0 +1,76 @@
+package lnkr
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Current schema version of .lnkr.toml.
+// Files without a version key are treated as version 0.
+const ConfigVersion = 1
+
+// configMigrations upgrade a configuration from version i to version i+1
+var configMigrations = []func(config *Config){
+	migrateConfigV0ToV1,
+}
+
+// migrateConfig upgrades a configuration in memory to the current schema version
+func migrateConfig(config *Config) error {
+	if config.Version < 0 {
+		return fmt.Errorf("invalid schema version: %d", config.Version)
+	}
+	if config.Version > ConfigVersion {
+		return fmt.Errorf("schema version %d is newer than supported version %d. Please upgrade lnkr", config.Version, ConfigVersion)
+	}
+
+	for config.Version < ConfigVersion {
+		configMigrations[config.Version](config)
+		config.Version++
+	}
+	return nil
+}
+
+// migrateConfigV0ToV1 fills in the default link type and normalizes link paths
+// (e.g. "config/" becomes "config")
+func migrateConfigV0ToV1(config *Config) {
+	for i, link := range config.Links {
+		if link.Type == "" {
+			config.Links[i].Type = LinkTypeHard
+		}
+		config.Links[i].Path = filepath.Clean(link.Path)
+	}
+}
+
+// MigrateConfig rewrites .lnkr.toml in the current schema version
+func MigrateConfig() error {
+	filename := ConfigFileName
+
+	content, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("%s not found. Run 'lnkr init' first", filename)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+
+	config, fromVersion, err := decodeConfig(content)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	if fromVersion == ConfigVersion {
+		fmt.Printf("%s is already at schema version %d\n", filename, ConfigVersion)
+		return nil
+	}
+
+	if err := saveConfig(config); err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+
+	fmt.Printf("Migrated %s from schema version %d to %d\n", filename, fromVersion, ConfigVersion)
+
+	// Link paths may have been normalized
+	updateIgnoreFile(config)
+	return nil
+}
@@ -0,0 +1,214 @@
+package lnkr
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Lines used by the picker besides the list: the help line and the status line
+const pickerChromeLines = 2
+
+// Number of list rows shown when the terminal size is unknown
+const pickerDefaultRows = 20
+
+// pickerItem is a candidate path in the picker
+type pickerItem struct {
+	Path     string
+	Type     string
+	Selected bool
+}
+
+// pickerKey is a key press understood by the picker
+type pickerKey int
+
+const (
+	keyNone pickerKey = iota
+	keyUp
+	keyDown
+	keyPageUp
+	keyPageDown
+	keyHome
+	keyEnd
+	keyToggle
+	keyToggleAll
+	keyCycleType
+	keyConfirm
+	keyCancel
+)
+
+// runPicker shows a keyboard-driven list of items on the terminal and returns the
+// selected items, or nil when the user cancels
+func runPicker(title string, items []pickerItem) ([]pickerItem, error) {
+	fd := int(os.Stdin.Fd())
+	term, err := makeRaw(fd)
+	if err != nil {
+		return nil, fmt.Errorf("interactive mode requires a terminal: %w", err)
+	}
+	defer term.restore()
+
+	out := bufio.NewWriter(os.Stdout)
+	// Switch to the alternate screen and hide the cursor while picking
+	fmt.Fprint(out, "\x1b[?1049h\x1b[?25l")
+	defer func() {
+		fmt.Fprint(out, "\x1b[?25h\x1b[?1049l")
+		out.Flush()
+	}()
+
+	cursor, offset := 0, 0
+	buf := make([]byte, 16)
+	for {
+		rows, cols, err := terminalSize(fd)
+		if err != nil || rows <= pickerChromeLines {
+			rows, cols = pickerDefaultRows+pickerChromeLines, 0
+		}
+		height := rows - pickerChromeLines
+
+		// Keep the cursor inside the visible window
+		if cursor < offset {
+			offset = cursor
+		}
+		if cursor >= offset+height {
+			offset = cursor - height + 1
+		}
+		renderPicker(out, title, items, cursor, offset, height, cols)
+		if err := out.Flush(); err != nil {
+			return nil, err
+		}
+
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+
+		switch parsePickerKey(buf[:n]) {
+		case keyUp:
+			cursor = max(cursor-1, 0)
+		case keyDown:
+			cursor = min(cursor+1, len(items)-1)
+		case keyPageUp:
+			cursor = max(cursor-height, 0)
+		case keyPageDown:
+			cursor = min(cursor+height, len(items)-1)
+		case keyHome:
+			cursor = 0
+		case keyEnd:
+			cursor = len(items) - 1
+		case keyToggle:
+			items[cursor].Selected = !items[cursor].Selected
+			cursor = min(cursor+1, len(items)-1)
+		case keyToggleAll:
+			all := true
+			for _, item := range items {
+				all = all && item.Selected
+			}
+			for i := range items {
+				items[i].Selected = !all
+			}
+		case keyCycleType:
+			items[cursor].Type = nextLinkType(items[cursor].Type)
+			items[cursor].Selected = true
+		case keyConfirm:
+			selected := []pickerItem{}
+			for _, item := range items {
+				if item.Selected {
+					selected = append(selected, item)
+				}
+			}
+			return selected, nil
+		case keyCancel:
+			return nil, nil
+		}
+	}
+}
+
+// parsePickerKey maps the bytes of a key press to a picker key
+func parsePickerKey(input []byte) pickerKey {
+	switch string(input) {
+	case "\x1b[A", "\x1bOA", "k", "\x10":
+		return keyUp
+	case "\x1b[B", "\x1bOB", "j", "\x0e":
+		return keyDown
+	case "\x1b[5~":
+		return keyPageUp
+	case "\x1b[6~":
+		return keyPageDown
+	case "\x1b[H", "\x1bOH", "g":
+		return keyHome
+	case "\x1b[F", "\x1bOF", "G":
+		return keyEnd
+	case " ", "x":
+		return keyToggle
+	case "a":
+		return keyToggleAll
+	case "t":
+		return keyCycleType
+	case "\r", "\n":
+		return keyConfirm
+	case "q", "\x1b", "\x03":
+		return keyCancel
+	}
+	return keyNone
+}
+
+// nextLinkType returns the link type following linkType in LinkTypes
+func nextLinkType(linkType string) string {
+	for i, t := range LinkTypes {
+		if t == linkType {
+			return LinkTypes[(i+1)%len(LinkTypes)]
+		}
+	}
+	return LinkTypes[0]
+}
+
+// renderPicker draws the visible part of the list
+func renderPicker(out *bufio.Writer, title string, items []pickerItem, cursor, offset, height, cols int) {
+	typeWidth := 0
+	for _, t := range LinkTypes {
+		typeWidth = max(typeWidth, len(t))
+	}
+
+	fmt.Fprint(out, "\x1b[H\x1b[2J")
+	writePickerLine(out, title+" (space: select, t: link type, a: all, enter: add, q: cancel)", cols)
+
+	end := min(offset+height, len(items))
+	for i := offset; i < end; i++ {
+		pointer, mark := "  ", "[ ]"
+		if i == cursor {
+			pointer = "> "
+		}
+		if items[i].Selected {
+			mark = "[x]"
+		}
+		line := fmt.Sprintf("%s%s %-*s  %s", pointer, mark, typeWidth, items[i].Type, items[i].Path)
+		if i == cursor {
+			// Reverse video for the line under the cursor
+			fmt.Fprint(out, "\x1b[7m")
+			writePickerLine(out, line, cols)
+			fmt.Fprint(out, "\x1b[0m")
+			continue
+		}
+		writePickerLine(out, line, cols)
+	}
+	for i := end - offset; i < height; i++ {
+		fmt.Fprint(out, "\n")
+	}
+
+	selected := 0
+	for _, item := range items {
+		if item.Selected {
+			selected++
+		}
+	}
+	fmt.Fprintf(out, "%d of %d selected (%d/%d)", selected, len(items), cursor+1, len(items))
+}
+
+// writePickerLine writes a line truncated to the terminal width
+func writePickerLine(out *bufio.Writer, line string, cols int) {
+	if runes := []rune(line); cols > 0 && len(runes) > cols {
+		line = string(runes[:cols])
+	}
+	out.WriteString(strings.TrimRight(line, " "))
+	out.WriteString("\x1b[K\n")
+}
@@ -3,41 +3,103 @@ package lnkr
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 )
 
-func Remove(path string) error {
+// Remove removes the links matching each path (the path itself and the links
+// under it) from the configuration, saving it once for the whole batch
+func Remove(paths []string, unlink bool) error {
 	config, err := loadConfig()
 	if err != nil {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 
-	var newLinks []Link
-	removed := false
-	for _, link := range config.Links {
-		if link.Path == path || strings.HasPrefix(link.Path, path+string(os.PathSeparator)) {
-			fmt.Printf("Removed link: %s\n", link.Path)
-			removed = true
+	remaining := config.Links
+	removed, skipped, failed := 0, 0, 0
+	for _, path := range paths {
+		path = filepath.Clean(path)
+
+		var kept []Link
+		var matched []Link
+		for _, link := range remaining {
+			if link.Path == path || strings.HasPrefix(link.Path, path+string(os.PathSeparator)) {
+				matched = append(matched, link)
+				continue
+			}
+			kept = append(kept, link)
+		}
+
+		if len(matched) == 0 {
+			if len(paths) > 1 {
+				fmt.Printf("No matching links found, skipping: %s\n", path)
+			}
+			skipped++
 			continue
 		}
-		newLinks = append(newLinks, link)
+
+		for _, link := range matched {
+			if unlink {
+				if err := unlinkRemovedLink(link, config); err != nil {
+					// Keep the entry so the link stays managed
+					fmt.Printf("Error removing link for %s: %v\n", link.Path, err)
+					kept = append(kept, link)
+					failed++
+					continue
+				}
+			}
+			fmt.Printf("Removed link: %s\n", link.Path)
+			removed++
+		}
+		remaining = kept
 	}
 
-	if !removed {
+	if removed == 0 && failed == 0 {
 		fmt.Println("No matching links found to remove.")
 		return nil
 	}
 
-	// pathで昇順ソート
-	sort.Slice(newLinks, func(i, j int) bool {
-		return newLinks[i].Path < newLinks[j].Path
-	})
+	if removed > 0 {
+		// pathで昇順ソート
+		sort.Slice(remaining, func(i, j int) bool {
+			return remaining[i].Path < remaining[j].Path
+		})
 
-	config.Links = newLinks
-	if err := saveConfig(config); err != nil {
-		return fmt.Errorf("failed to save configuration: %w", err)
+		config.Links = remaining
+		if err := saveConfig(config); err != nil {
+			return fmt.Errorf("failed to save configuration: %w", err)
+		}
+
+		updateIgnoreFile(config)
 	}
 
+	if len(paths) > 1 {
+		fmt.Printf("Summary: %d removed, %d skipped, %d failed\n", removed, skipped, failed)
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to remove %d link(s)", failed)
+	}
 	return nil
 }
+
+// unlinkRemovedLink removes the link of a removed entry from the filesystem,
+// refusing when the local side is not in sync with the remote
+func unlinkRemovedLink(link Link, config *Config) error {
+	localAbs := filepath.Join(config.Local, link.Path)
+	if _, err := os.Lstat(localAbs); os.IsNotExist(err) {
+		fmt.Printf("Path does not exist, skipping: %s\n", localAbs)
+		return nil
+	}
+
+	absRemote, err := filepath.Abs(config.Remote)
+	if err != nil {
+		return fmt.Errorf("invalid remote directory path: %w", err)
+	}
+	if err := checkInSync(link, localAbs, filepath.Join(absRemote, link.Path)); err != nil {
+		return fmt.Errorf("%w. Check with 'lnkr diff %s' before removing", err, link.Path)
+	}
+
+	journalTouchLink(link, localAbs, filepath.Join(absRemote, link.Path))
+	return removeLinkWithBase(link, config.Local)
+}
@@ -0,0 +1,333 @@
+package lnkr
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Files larger than this are not scanned for secrets
+const secretScanMaxSize = 1 << 20
+
+// Minimum length and Shannon entropy (bits per character) of a high-entropy string
+const (
+	secretMinLength  = 20
+	secretMinEntropy = 3.5
+)
+
+// Minimum length and entropy of a hex string. Hex carries at most 4 bits per
+// character, so it needs a lower entropy than base64 and a longer token.
+const (
+	secretMinHexLength  = 32
+	secretMinHexEntropy = 3.0
+)
+
+// secretPattern is a common credential format
+type secretPattern struct {
+	name string
+	re   *regexp.Regexp
+}
+
+var secretPatterns = []secretPattern{
+	{"private key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`)},
+	{"AWS access key ID", regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"GitHub token", regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{"GitLab token", regexp.MustCompile(`\bglpat-[A-Za-z0-9_-]{20,}\b`)},
+	{"Slack token", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}\b`)},
+	{"Google API key", regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+	{"Stripe secret key", regexp.MustCompile(`\b[sr]k_live_[0-9A-Za-z]{16,}\b`)},
+	{"JSON web token", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\b`)},
+	{"credentials in URL", regexp.MustCompile(`[a-z][a-z0-9+.-]*://[^/\s:@]+:[^/\s:@]+@`)},
+	{"password or secret assignment", regexp.MustCompile(`(?i)\b[a-z0-9_.-]*(password|passwd|secret|token|api[_-]?key|access[_-]?key)[a-z0-9_.-]*\s*[:=]\s*["']?[^\s"'#]{8,}`)},
+}
+
+// Candidate high-entropy strings: runs of base64, base64url and hex characters
+var secretTokenRe = regexp.MustCompile(`[A-Za-z0-9+/=_-]{20,}`)
+
+// Candidate hex keys: runs of lower or upper case hex digits
+var secretHexTokenRe = regexp.MustCompile(`[0-9a-f]{32,}|[0-9A-F]{32,}`)
+
+// secretFinding is a line that looks like it holds a secret
+type secretFinding struct {
+	// File relative to the scanned path's base directory
+	Path string
+	Line int
+	Kind string
+}
+
+// scanSecrets scans a file, or the files under a directory, for credential
+// patterns and high-entropy strings. Binary and large files are skipped.
+func scanSecrets(baseDir, path string) ([]secretFinding, error) {
+	var findings []secretFinding
+	root := filepath.Join(baseDir, path)
+	err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() || info.Size() > secretScanMaxSize {
+			return nil
+		}
+
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		if isBinary(content) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(baseDir, p)
+		if err != nil {
+			return err
+		}
+		for _, f := range scanSecretContent(content) {
+			f.Path = rel
+			findings = append(findings, f)
+		}
+		return nil
+	})
+	return findings, err
+}
+
+// scanSecretContent returns the lines of content that look like they hold a secret
+func scanSecretContent(content []byte) []secretFinding {
+	var findings []secretFinding
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 64*1024), secretScanMaxSize)
+	for line := 1; scanner.Scan(); line++ {
+		if kind := secretKind(scanner.Text()); kind != "" {
+			findings = append(findings, secretFinding{Line: line, Kind: kind})
+		}
+	}
+	return findings
+}
+
+// secretKind returns what a line looks like it holds, or an empty string
+func secretKind(line string) string {
+	for _, pattern := range secretPatterns {
+		if pattern.re.MatchString(line) {
+			return pattern.name
+		}
+	}
+	for _, token := range secretTokenRe.FindAllString(line, -1) {
+		if isHighEntropy(token) {
+			return "high-entropy string"
+		}
+	}
+	for _, token := range secretHexTokenRe.FindAllString(line, -1) {
+		if isHighEntropyHex(token) {
+			return "high-entropy string"
+		}
+	}
+	return ""
+}
+
+// isHighEntropy reports whether a token looks like random key material: mixed
+// case letters and digits with a high Shannon entropy
+func isHighEntropy(token string) bool {
+	if len(token) < secretMinLength {
+		return false
+	}
+	hasLower := strings.ContainsAny(token, "abcdefghijklmnopqrstuvwxyz")
+	hasUpper := strings.ContainsAny(token, "ABCDEFGHIJKLMNOPQRSTUVWXYZ")
+	hasDigit := strings.ContainsAny(token, "0123456789")
+	if !hasLower || !hasUpper || !hasDigit {
+		return false
+	}
+	return shannonEntropy(token) >= secretMinEntropy
+}
+
+// isHighEntropyHex reports whether a hex token looks like random key material:
+// letters and digits with a high Shannon entropy
+func isHighEntropyHex(token string) bool {
+	if len(token) < secretMinHexLength {
+		return false
+	}
+	hasLetter := strings.ContainsAny(token, "abcdefABCDEF")
+	hasDigit := strings.ContainsAny(token, "0123456789")
+	if !hasLetter || !hasDigit {
+		return false
+	}
+	return shannonEntropy(token) >= secretMinHexEntropy
+}
+
+// shannonEntropy returns the entropy of s in bits per character
+func shannonEntropy(s string) float64 {
+	counts := make(map[rune]int)
+	for _, r := range s {
+		counts[r]++
+	}
+	entropy := 0.0
+	n := float64(len(s))
+	for _, c := range counts {
+		p := float64(c) / n
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// scanLinksForSecrets scans the new links for secrets and prints the findings.
+// It returns the paths of the links that look like they hold secrets.
+func scanLinksForSecrets(links []Link, baseDir string) ([]string, error) {
+	var flagged []string
+	for _, link := range links {
+		// The remote of an encrypted link never holds the plain content
+		if link.Type == LinkTypeEncrypted {
+			continue
+		}
+
+		findings, err := scanSecrets(baseDir, link.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan %s for secrets: %w", link.Path, err)
+		}
+		if len(findings) == 0 {
+			continue
+		}
+
+		flagged = append(flagged, link.Path)
+		fmt.Printf("Warning: %s may contain secrets:\n", link.Path)
+		for _, f := range findings {
+			fmt.Printf("  %s:%d: %s\n", f.Path, f.Line, f.Kind)
+		}
+		if isRegularFile(filepath.Join(baseDir, link.Path)) {
+			fmt.Printf("  To keep the remote encrypted, run 'lnkr remove %s' and add it again with --encrypted,\n", link.Path)
+			fmt.Printf("  or restrict it with 'lnkr config set %s%s.mode 0600'\n", linkKeyPrefix, link.Path)
+		} else {
+			fmt.Printf("  To keep the remote encrypted, run 'lnkr remove %s' and add its files again with --encrypted --recursive\n", link.Path)
+		}
+	}
+	return flagged, nil
+}
+
+// isRegularFile reports whether path is a regular file
+func isRegularFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// verifyExcluded checks, before anything is written, that the LNKR section of the
+// ignore file will exclude paths. Patterns must read back as the same path, and for
+// the git backends git check-ignore must agree, which catches negations in other
+// ignore files. Negations are checked conservatively: they are assumed to win over
+// the LNKR section wherever they are.
+func verifyExcluded(config *Config, paths []string) error {
+	writer, err := newIgnoreWriter(config)
+	if err != nil {
+		return err
+	}
+
+	var missing, candidates []string
+	for _, path := range paths {
+		if writer.ParsePattern(writer.Pattern(path)) != path {
+			fmt.Printf("Not excluded: %s cannot be written to %s as is\n", path, writer.Path())
+			missing = append(missing, path)
+			continue
+		}
+		candidates = append(candidates, path)
+	}
+
+	backend := config.GetIgnoreBackend()
+	if len(candidates) > 0 && (backend == IgnoreBackendGitExclude || backend == IgnoreBackendGitignore) {
+		ignored, err := gitCheckIgnore(config, writer, candidates)
+		if err != nil {
+			return err
+		}
+		var kept []string
+		for _, path := range candidates {
+			if _, ok := ignored[path]; !ok {
+				fmt.Printf("Not excluded: %s is re-included by another ignore rule (check with 'git check-ignore -v --no-index %s')\n", path, path)
+				missing = append(missing, path)
+				continue
+			}
+			kept = append(kept, path)
+		}
+		candidates = kept
+	}
+
+	for _, path := range candidates {
+		fmt.Printf("Verified: %s will be excluded by %s\n", path, writer.Path())
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%d path(s) that may contain secrets would not be excluded by %s. Fix the ignore rules, or use --force to add them anyway", len(missing), writer.Path())
+	}
+	return nil
+}
+
+// gitCheckIgnore returns the paths, relative to the project root, that git ignores
+// once the LNKR patterns for them are added. The patterns are passed through a
+// temporary core.excludesFile, the ignore file with the lowest precedence.
+// Projects outside a git repository ignore every path.
+func gitCheckIgnore(config *Config, writer IgnoreWriter, paths []string) (map[string]struct{}, error) {
+	ignored := make(map[string]struct{}, len(paths))
+	top, _, err := findWorkTree(config.Local)
+	if err != nil {
+		for _, path := range paths {
+			ignored[path] = struct{}{}
+		}
+		return ignored, nil
+	}
+
+	// core.excludesFile is read relative to the top of the work tree, like the git
+	// exclude file; .gitignore entries are relative to the project root
+	var patterns strings.Builder
+	for _, path := range paths {
+		pattern := writer.Pattern(path)
+		if config.GetIgnoreBackend() == IgnoreBackendGitignore {
+			abs, err := filepath.Abs(filepath.Join(config.Local, path))
+			if err != nil {
+				return nil, err
+			}
+			rel, err := filepath.Rel(top, abs)
+			if err != nil {
+				return nil, err
+			}
+			pattern = writer.Pattern(rel)
+		}
+		patterns.WriteString(pattern + "\n")
+	}
+
+	tmp, err := os.CreateTemp("", "lnkr-exclude-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(patterns.String()); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command("git", "-c", "core.excludesFile="+tmp.Name(), "check-ignore", "--no-index", "-z", "--stdin")
+	cmd.Dir = config.Local
+	cmd.Stdin = strings.NewReader(strings.Join(paths, "\x00") + "\x00")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		// Exit status 1 means that none of the paths is ignored
+		if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 1 {
+			return nil, fmt.Errorf("git check-ignore failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+		}
+	}
+
+	for _, path := range strings.Split(stdout.String(), "\x00") {
+		if path != "" {
+			ignored[filepath.FromSlash(path)] = struct{}{}
+		}
+	}
+	return ignored, nil
+}
@@ -0,0 +1,507 @@
+package lnkr
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Prefix of per-link setting keys, e.g. link.config/app.yml.type
+const linkKeyPrefix = "link."
+
+// configSetting describes a top-level setting of .lnkr.toml
+type configSetting struct {
+	get func(config *Config) string
+	// set validates and applies a value; nil means the setting is read-only
+	set func(config *Config, value string) error
+	// unset clears the setting; nil means the setting is required
+	unset func(config *Config) error
+}
+
+// linkSetting describes a per-link setting of .lnkr.toml
+type linkSetting struct {
+	get func(link *Link) string
+	set func(link *Link, value string) error
+	// unset clears the setting; nil means the setting is required
+	unset func(link *Link)
+}
+
+// configSettingNames lists the top-level settings in the order they are listed
+var configSettingNames = []string{"version", "local", "remote", "git_exclude_path", "ignore_backend"}
+
+var configSettings = map[string]configSetting{
+	"version": {
+		get: func(config *Config) string { return strconv.Itoa(config.Version) },
+	},
+	"local": {
+		get: func(config *Config) string { return config.Local },
+		set: func(config *Config, value string) error {
+			dir, err := absDir(value)
+			if err != nil {
+				return err
+			}
+			config.Local = dir
+			return nil
+		},
+	},
+	"remote": {
+		get: func(config *Config) string { return config.Remote },
+		set: func(config *Config, value string) error {
+			dir, err := absDir(value)
+			if err != nil {
+				return err
+			}
+			config.Remote = dir
+			return nil
+		},
+		unset: func(config *Config) error {
+			config.Remote = ""
+			return nil
+		},
+	},
+	"git_exclude_path": {
+		get: func(config *Config) string { return config.GitExcludePath },
+		set: func(config *Config, value string) error {
+			if value == "" {
+				return fmt.Errorf("git_exclude_path cannot be empty. Use 'lnkr config unset git_exclude_path' to use the default")
+			}
+			config.GitExcludePath = value
+			return nil
+		},
+		unset: func(config *Config) error {
+			config.GitExcludePath = ""
+			return nil
+		},
+	},
+	"ignore_backend": {
+		get: func(config *Config) string { return config.GetIgnoreBackend() },
+		set: func(config *Config, value string) error {
+			if !isValidIgnoreBackend(value) {
+				return fmt.Errorf("invalid ignore backend: %s. Must be one of: %s", value, strings.Join(IgnoreBackends, ", "))
+			}
+			config.IgnoreBackend = value
+			return nil
+		},
+		unset: func(config *Config) error {
+			config.IgnoreBackend = ""
+			return nil
+		},
+	},
+}
+
+// linkSettingNames lists the per-link settings in the order they are listed
+var linkSettingNames = []string{"type", "mode", "owner", "template"}
+
+var linkSettings = map[string]linkSetting{
+	"type": {
+		get: func(link *Link) string { return link.Type },
+		set: func(link *Link, value string) error {
+			if !isValidLinkType(value) {
+				return fmt.Errorf("invalid link type: %s. Must be one of: %s", value, strings.Join(LinkTypes, ", "))
+			}
+			if link.Template && value != LinkTypeCopy {
+				return fmt.Errorf("%s is rendered from a template and must stay a %s link", link.Path, LinkTypeCopy)
+			}
+			link.Type = value
+			return nil
+		},
+	},
+	"mode": {
+		get: func(link *Link) string { return link.Mode },
+		set: func(link *Link, value string) error {
+			if value != "" {
+				if _, err := parseLinkMode(value); err != nil {
+					return fmt.Errorf("invalid mode: %s: %w", value, err)
+				}
+			}
+			link.Mode = value
+			return nil
+		},
+		unset: func(link *Link) { link.Mode = "" },
+	},
+	"owner": {
+		get: func(link *Link) string { return link.Owner },
+		set: func(link *Link, value string) error {
+			if value != "" {
+				if _, _, err := parseLinkOwner(value); err != nil {
+					return fmt.Errorf("invalid owner: %s: %w", value, err)
+				}
+			}
+			link.Owner = value
+			return nil
+		},
+		unset: func(link *Link) { link.Owner = "" },
+	},
+	"template": {
+		get: func(link *Link) string { return strconv.FormatBool(link.Template) },
+		set: func(link *Link, value string) error {
+			template, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid template value: %s. Must be true or false", value)
+			}
+			if template && link.Type != LinkTypeCopy {
+				return fmt.Errorf("template requires link type %s. Run 'lnkr convert %s --to %s' first", LinkTypeCopy, link.Path, LinkTypeCopy)
+			}
+			link.Template = template
+			return nil
+		},
+		unset: func(link *Link) { link.Template = false },
+	},
+}
+
+// ConfigGet prints the value of a setting
+func ConfigGet(key string) error {
+	config, err := loadExistingConfig()
+	if err != nil {
+		return err
+	}
+
+	value, err := getSetting(config, key)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(value)
+	return nil
+}
+
+// ConfigSet validates and stores the value of a setting
+func ConfigSet(key, value string) error {
+	config, err := loadExistingConfig()
+	if err != nil {
+		return err
+	}
+
+	// The ignore file may move to another file or backend
+	previous, _ := newIgnoreWriter(config)
+
+	if linkPath, name, ok := parseLinkKey(key); ok {
+		link, setting, err := lookupLinkSetting(config, linkPath, name)
+		if err != nil {
+			return err
+		}
+		if err := setting.set(link, value); err != nil {
+			return err
+		}
+	} else {
+		setting, ok := configSettings[key]
+		if !ok {
+			return unknownKeyError(key)
+		}
+		if setting.set == nil {
+			return fmt.Errorf("%s is read-only", key)
+		}
+		if err := setting.set(config, value); err != nil {
+			return err
+		}
+	}
+
+	if err := saveConfig(config); err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+
+	newValue, _ := getSetting(config, key)
+	fmt.Printf("Set %s = %s\n", key, newValue)
+
+	// Keep the ignore file in sync (git_exclude_path or ignore_backend may have changed)
+	clearPreviousIgnoreFile(previous, config)
+	updateIgnoreFile(config)
+	return nil
+}
+
+// ConfigUnset clears an optional setting
+func ConfigUnset(key string) error {
+	config, err := loadExistingConfig()
+	if err != nil {
+		return err
+	}
+
+	if linkPath, name, ok := parseLinkKey(key); ok {
+		link, setting, err := lookupLinkSetting(config, linkPath, name)
+		if err != nil {
+			return err
+		}
+		if setting.unset == nil {
+			return fmt.Errorf("%s cannot be unset. Use 'lnkr remove' to remove a link", key)
+		}
+		setting.unset(link)
+
+		if err := saveConfig(config); err != nil {
+			return fmt.Errorf("failed to save configuration: %w", err)
+		}
+		fmt.Printf("Unset %s\n", key)
+		return nil
+	}
+
+	setting, ok := configSettings[key]
+	if !ok {
+		return unknownKeyError(key)
+	}
+	if setting.unset == nil {
+		return fmt.Errorf("%s is required and cannot be unset", key)
+	}
+
+	// The ignore file may move to another file or backend
+	previous, _ := newIgnoreWriter(config)
+
+	if err := setting.unset(config); err != nil {
+		return err
+	}
+
+	if err := saveConfig(config); err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+
+	fmt.Printf("Unset %s\n", key)
+
+	// Keep the ignore file in sync (git_exclude_path or ignore_backend may have changed)
+	clearPreviousIgnoreFile(previous, config)
+	updateIgnoreFile(config)
+	return nil
+}
+
+// ConfigList prints every setting as key = value
+func ConfigList() error {
+	config, err := loadExistingConfig()
+	if err != nil {
+		return err
+	}
+
+	for _, name := range configSettingNames {
+		fmt.Printf("%s = %s\n", name, configSettings[name].get(config))
+	}
+	for i := range config.Links {
+		for _, name := range linkSettingNames {
+			fmt.Printf("%s%s.%s = %s\n", linkKeyPrefix, config.Links[i].Path, name, linkSettings[name].get(&config.Links[i]))
+		}
+	}
+	return nil
+}
+
+// ConfigEdit opens .lnkr.toml in $VISUAL or $EDITOR and validates it afterwards
+func ConfigEdit() error {
+	before, err := loadExistingConfig()
+	if err != nil {
+		return err
+	}
+	// The ignore file may move to another file or backend
+	previous, _ := newIgnoreWriter(before)
+
+	// A blank variable counts as unset
+	editor := strings.TrimSpace(os.Getenv("VISUAL"))
+	if editor == "" {
+		editor = strings.TrimSpace(os.Getenv("EDITOR"))
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	// The editor may contain arguments (e.g. "code --wait")
+	fields := strings.Fields(editor)
+	if len(fields) == 0 {
+		fields = []string{"vi"}
+	}
+	journalTouchConfig(ConfigFileName)
+	cmd := exec.Command(fields[0], append(fields[1:], ConfigFileName)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor %s failed: %w", editor, err)
+	}
+
+	if err := ValidateConfigFile(); err != nil {
+		return fmt.Errorf("%w. Run 'lnkr config edit' again to fix it", err)
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if err := mirrorConfigToRemote(config.Remote); err != nil {
+		fmt.Printf("Warning: failed to mirror %s to remote: %v\n", ConfigFileName, err)
+	}
+
+	// Links may have been added or removed by hand
+	clearPreviousIgnoreFile(previous, config)
+	updateIgnoreFile(config)
+	return nil
+}
+
+// ValidateConfigFile loads .lnkr.toml and reports every problem found in it
+func ValidateConfigFile() error {
+	content, err := os.ReadFile(ConfigFileName)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("%s not found. Run 'lnkr init' first", ConfigFileName)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", ConfigFileName, err)
+	}
+
+	// Link types are checked by ValidateConfig so that every problem is reported
+	config, _, err := parseConfig(content)
+	if err != nil {
+		return fmt.Errorf("%s is invalid: %w", ConfigFileName, err)
+	}
+
+	problems := ValidateConfig(config)
+	if len(problems) > 0 {
+		for _, problem := range problems {
+			fmt.Printf("%s: %v\n", ConfigFileName, problem)
+		}
+		return fmt.Errorf("%s has %d problem(s)", ConfigFileName, len(problems))
+	}
+
+	fmt.Printf("%s is valid (%d links)\n", ConfigFileName, len(config.Links))
+	return nil
+}
+
+// ValidateConfig checks the settings and that every link path is relative,
+// normalized, unique and of a known type
+func ValidateConfig(config *Config) []error {
+	var problems []error
+
+	if config.Local == "" {
+		problems = append(problems, fmt.Errorf("local is not set"))
+	} else if !filepath.IsAbs(config.Local) {
+		problems = append(problems, fmt.Errorf("local is not an absolute path: %s", config.Local))
+	}
+	if config.Remote != "" && !filepath.IsAbs(config.Remote) {
+		problems = append(problems, fmt.Errorf("remote is not an absolute path: %s", config.Remote))
+	}
+
+	if config.IgnoreBackend != "" && !isValidIgnoreBackend(config.IgnoreBackend) {
+		problems = append(problems, fmt.Errorf("invalid ignore backend %q. Must be one of: %s", config.IgnoreBackend, strings.Join(IgnoreBackends, ", ")))
+	}
+
+	seen := make(map[string]struct{})
+	for _, link := range config.Links {
+		switch {
+		case link.Path == "":
+			problems = append(problems, fmt.Errorf("link with empty path"))
+			continue
+		case filepath.IsAbs(link.Path):
+			problems = append(problems, fmt.Errorf("link path is absolute: %s", link.Path))
+		case filepath.Clean(link.Path) != link.Path || link.Path == ".":
+			problems = append(problems, fmt.Errorf("link path is not normalized: %s (expected: %s)", link.Path, filepath.Clean(link.Path)))
+		case link.Path == ".." || strings.HasPrefix(link.Path, ".."+string(os.PathSeparator)):
+			problems = append(problems, fmt.Errorf("link path is outside the project: %s", link.Path))
+		}
+
+		if _, ok := seen[link.Path]; ok {
+			problems = append(problems, fmt.Errorf("duplicate link path: %s", link.Path))
+		}
+		seen[link.Path] = struct{}{}
+
+		if !isValidLinkType(link.Type) {
+			problems = append(problems, fmt.Errorf("invalid link type %q for %s", link.Type, link.Path))
+		}
+		if err := validateLinkOptions(link); err != nil {
+			problems = append(problems, err)
+		}
+	}
+
+	// Links nested inside a symbolic directory link would be linked twice
+	for _, link := range config.Links {
+		if link.Type != LinkTypeSymbolic {
+			continue
+		}
+		for _, other := range config.Links {
+			if strings.HasPrefix(other.Path, link.Path+string(os.PathSeparator)) {
+				problems = append(problems, fmt.Errorf("link %s is inside symbolic link %s", other.Path, link.Path))
+			}
+		}
+	}
+
+	return problems
+}
+
+// loadExistingConfig loads .lnkr.toml, failing if the project is not initialized
+func loadExistingConfig() (*Config, error) {
+	if _, err := os.Stat(ConfigFileName); os.IsNotExist(err) {
+		return nil, fmt.Errorf("%s not found. Run 'lnkr init' first", ConfigFileName)
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	return config, nil
+}
+
+// getSetting returns the value of a top-level or per-link setting
+func getSetting(config *Config, key string) (string, error) {
+	if linkPath, name, ok := parseLinkKey(key); ok {
+		link, setting, err := lookupLinkSetting(config, linkPath, name)
+		if err != nil {
+			return "", err
+		}
+		return setting.get(link), nil
+	}
+
+	setting, ok := configSettings[key]
+	if !ok {
+		return "", unknownKeyError(key)
+	}
+	return setting.get(config), nil
+}
+
+// parseLinkKey splits a key of the form link.<path>.<setting>
+func parseLinkKey(key string) (linkPath, name string, ok bool) {
+	if !strings.HasPrefix(key, linkKeyPrefix) {
+		return "", "", false
+	}
+	rest := strings.TrimPrefix(key, linkKeyPrefix)
+	i := strings.LastIndex(rest, ".")
+	if i <= 0 || i == len(rest)-1 {
+		return "", "", false
+	}
+	return rest[:i], rest[i+1:], true
+}
+
+// lookupLinkSetting finds the link with the given path and the named per-link setting
+func lookupLinkSetting(config *Config, linkPath, name string) (*Link, linkSetting, error) {
+	setting, ok := linkSettings[name]
+	if !ok {
+		return nil, linkSetting{}, fmt.Errorf("unknown link setting: %s. Must be one of: %s", name, strings.Join(linkSettingNames, ", "))
+	}
+
+	linkPath = filepath.Clean(linkPath)
+	for i := range config.Links {
+		if config.Links[i].Path == linkPath {
+			return &config.Links[i], setting, nil
+		}
+	}
+	return nil, linkSetting{}, fmt.Errorf("link not found: %s", linkPath)
+}
+
+// unknownKeyError returns an error listing the known keys
+func unknownKeyError(key string) error {
+	keys := append([]string{}, configSettingNames...)
+	sort.Strings(keys)
+	return fmt.Errorf("unknown key: %s. Must be one of: %s, %s<path>.<%s>", key, strings.Join(keys, ", "), linkKeyPrefix, strings.Join(linkSettingNames, "|"))
+}
+
+// absDir converts a path to an absolute path and checks that it is an existing directory
+func absDir(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to convert to absolute path: %w", err)
+	}
+
+	info, err := os.Stat(abs)
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("directory does not exist: %s", abs)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to stat directory: %w", err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("path exists but is not a directory: %s", abs)
+	}
+	return abs, nil
+}
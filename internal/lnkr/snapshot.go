@@ -0,0 +1,487 @@
+package lnkr
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Directory in the remote holding the snapshots
+const HistoryDirName = ".lnkr-history"
+
+// Layout of the history directory
+const (
+	historyObjectsDir   = "objects"
+	historySnapshotsDir = "snapshots"
+	snapshotIDFormat    = "20060102T150405Z"
+)
+
+// Sides of a link a snapshot file was taken from
+const (
+	snapshotSideRemote = "remote"
+	snapshotSideLocal  = "local"
+)
+
+// snapshotManifest lists the files of a snapshot
+type snapshotManifest struct {
+	ID      string         `json:"id"`
+	Created time.Time      `json:"created"`
+	Reason  string         `json:"reason,omitempty"`
+	Files   []snapshotFile `json:"files"`
+}
+
+// snapshotFile is a file of a snapshot, stored as a content-addressed object
+type snapshotFile struct {
+	Side string `json:"side"`
+	// Path relative to the local or remote directory
+	Path string `json:"path"`
+	// Path of the link the file belongs to
+	Link string `json:"link"`
+	// SHA-256 of the content, naming the object
+	Hash string `json:"hash"`
+	Mode string `json:"mode"`
+	Size int64  `json:"size"`
+}
+
+// SnapshotCreate stores a snapshot of the linked files
+func SnapshotCreate(message string) error {
+	config, err := loadExistingConfig()
+	if err != nil {
+		return err
+	}
+
+	manifest, err := createSnapshot(config, message)
+	if err != nil {
+		return err
+	}
+	if manifest == nil {
+		fmt.Println("No linked files to snapshot.")
+		return nil
+	}
+	fmt.Printf("Created snapshot %s (%d files)\n", manifest.ID, len(manifest.Files))
+	return nil
+}
+
+// SnapshotList prints the snapshots, oldest first
+func SnapshotList() error {
+	config, err := loadExistingConfig()
+	if err != nil {
+		return err
+	}
+
+	manifests, err := loadSnapshots(config)
+	if err != nil {
+		return err
+	}
+	if len(manifests) == 0 {
+		fmt.Println("No snapshots found.")
+		return nil
+	}
+
+	printSnapshotTable(manifests)
+	return nil
+}
+
+// SnapshotRestore writes the files of a snapshot back in place, limited to the
+// links at or under paths when given. Files are rewritten rather than replaced,
+// so hard links keep sharing the restored content. The current state is
+// snapshotted first so that the restore can be undone.
+func SnapshotRestore(id string, paths []string) error {
+	config, err := loadExistingConfig()
+	if err != nil {
+		return err
+	}
+
+	manifest, err := loadSnapshot(config, id)
+	if err != nil {
+		return err
+	}
+
+	links, err := selectLinks(&Config{Links: snapshotLinks(manifest)}, paths)
+	if err != nil {
+		return err
+	}
+	selected := make(map[string]struct{}, len(links))
+	for _, link := range links {
+		selected[link.Path] = struct{}{}
+	}
+
+	previous, err := autoSnapshot(config, "before restore of "+manifest.ID)
+	if err != nil {
+		return err
+	}
+
+	absRemote, err := filepath.Abs(config.Remote)
+	if err != nil {
+		return fmt.Errorf("invalid remote directory path: %w", err)
+	}
+
+	restored, failed := 0, 0
+	for _, f := range manifest.Files {
+		if _, ok := selected[f.Link]; !ok {
+			continue
+		}
+		if err := validateSnapshotFile(f); err != nil {
+			fmt.Printf("Error restoring %s: %v\n", f.Path, err)
+			failed++
+			continue
+		}
+		base := absRemote
+		if f.Side == snapshotSideLocal {
+			base = config.Local
+		}
+		target := filepath.Join(base, f.Path)
+		if err := restoreSnapshotFile(config, f, target); err != nil {
+			fmt.Printf("Error restoring %s: %v\n", target, err)
+			failed++
+			continue
+		}
+		fmt.Printf("Restored %s\n", target)
+		restored++
+	}
+
+	fmt.Printf("Restored %d file(s) from snapshot %s\n", restored, manifest.ID)
+	if previous != "" {
+		fmt.Printf("Undo with 'lnkr snapshot restore %s'\n", previous)
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to restore %d file(s)", failed)
+	}
+	return nil
+}
+
+// autoSnapshot takes a snapshot before a destructive command and reports it.
+// It returns the snapshot ID, or an empty string when there was nothing to store.
+func autoSnapshot(config *Config, reason string) (string, error) {
+	manifest, err := createSnapshot(config, reason)
+	if err != nil {
+		return "", fmt.Errorf("failed to create snapshot %s: %w", reason, err)
+	}
+	if manifest == nil {
+		return "", nil
+	}
+	fmt.Printf("Created snapshot %s (%s)\n", manifest.ID, reason)
+	return manifest.ID, nil
+}
+
+// createSnapshot stores the remote files of every link, and the local files that
+// do not share their content with the remote. The local plain text of encrypted
+// links is never stored. It returns nil when there is nothing to snapshot.
+func createSnapshot(config *Config, reason string) (*snapshotManifest, error) {
+	if config.Remote == "" {
+		return nil, fmt.Errorf("remote directory not configured. Run 'lnkr init --remote <path>' first")
+	}
+	absRemote, err := filepath.Abs(config.Remote)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote directory path: %w", err)
+	}
+	historyDir := filepath.Join(absRemote, HistoryDirName)
+
+	manifest := &snapshotManifest{Created: time.Now().UTC(), Reason: reason}
+	for _, link := range config.Links {
+		remoteFiles, err := snapshotFiles(historyDir, absRemote, link, snapshotSideRemote, nil)
+		if err != nil {
+			return nil, err
+		}
+		manifest.Files = append(manifest.Files, remoteFiles...)
+
+		if link.Type == LinkTypeEncrypted {
+			continue
+		}
+		localFiles, err := snapshotFiles(historyDir, config.Local, link, snapshotSideLocal, func(rel string, info os.FileInfo) bool {
+			// Hard links share their content with the remote
+			remoteInfo, err := os.Stat(filepath.Join(absRemote, rel))
+			return err != nil || !os.SameFile(info, remoteInfo)
+		})
+		if err != nil {
+			return nil, err
+		}
+		manifest.Files = append(manifest.Files, localFiles...)
+	}
+	if len(manifest.Files) == 0 {
+		return nil, nil
+	}
+
+	snapshotsDir := filepath.Join(historyDir, historySnapshotsDir)
+	if err := os.MkdirAll(snapshotsDir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	// Snapshots taken within the same second get a numeric suffix
+	manifest.ID = manifest.Created.Format(snapshotIDFormat)
+	for i := 2; ; i++ {
+		if _, err := os.Stat(filepath.Join(snapshotsDir, manifest.ID+".json")); os.IsNotExist(err) {
+			break
+		}
+		manifest.ID = fmt.Sprintf("%s-%d", manifest.Created.Format(snapshotIDFormat), i)
+	}
+
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFileAtomic(filepath.Join(snapshotsDir, manifest.ID+".json"), append(content, '\n'), 0600); err != nil {
+		return nil, fmt.Errorf("failed to write snapshot manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+// snapshotFiles stores the regular files of a link under base as objects and
+// returns their entries. Symbolic links are not followed. include, if set,
+// filters the files by their path relative to base.
+func snapshotFiles(historyDir, base string, link Link, side string, include func(rel string, info os.FileInfo) bool) ([]snapshotFile, error) {
+	root := filepath.Join(base, link.Path)
+	if _, err := os.Lstat(root); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	var files []snapshotFile
+	err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(base, p)
+		if err != nil {
+			return err
+		}
+		if include != nil && !include(rel, info) {
+			return nil
+		}
+
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		hash, err := storeObject(filepath.Join(historyDir, historyObjectsDir), content)
+		if err != nil {
+			return fmt.Errorf("failed to store %s: %w", p, err)
+		}
+		files = append(files, snapshotFile{
+			Side: side,
+			Path: rel,
+			Link: link.Path,
+			Hash: hash,
+			Mode: fmt.Sprintf("%04o", info.Mode().Perm()),
+			Size: info.Size(),
+		})
+		return nil
+	})
+	return files, err
+}
+
+// storeObject stores content in objectsDir under its SHA-256, unless an object
+// with the same content already exists, and returns the hash
+func storeObject(objectsDir string, content []byte) (string, error) {
+	hash := contentHash(content)
+	path := objectPath(objectsDir, hash)
+	if _, err := os.Stat(path); err == nil {
+		return hash, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", err
+	}
+	return hash, writeFileAtomic(path, content, 0600)
+}
+
+// objectPath returns the path of the object with the given hash
+func objectPath(objectsDir, hash string) string {
+	return filepath.Join(objectsDir, hash[:2], hash[2:])
+}
+
+// readObject reads the object with the given hash and checks that its content
+// matches the hash. The hash comes from a manifest, which may have been edited.
+func readObject(objectsDir, hash string) ([]byte, error) {
+	if !isContentHash(hash) {
+		return nil, fmt.Errorf("invalid object hash: %q", hash)
+	}
+	content, err := os.ReadFile(objectPath(objectsDir, hash))
+	if err != nil {
+		return nil, fmt.Errorf("missing object %s: %w", hash, err)
+	}
+	if contentHash(content) != hash {
+		return nil, fmt.Errorf("corrupt object %s: content does not match its hash", hash)
+	}
+	return content, nil
+}
+
+// isContentHash reports whether s is a hex SHA-256 as returned by contentHash
+func isContentHash(s string) bool {
+	if len(s) != 2*sha256.Size {
+		return false
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// contentHash returns the hex SHA-256 of content
+func contentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// validateSnapshotFile checks a manifest entry before it is restored. Manifests
+// are read from the remote, so an entry must not point outside its side.
+func validateSnapshotFile(f snapshotFile) error {
+	if f.Side != snapshotSideRemote && f.Side != snapshotSideLocal {
+		return fmt.Errorf("invalid side %q in snapshot", f.Side)
+	}
+	if !filepath.IsLocal(f.Path) {
+		return fmt.Errorf("invalid path %q in snapshot", f.Path)
+	}
+	if !isContentHash(f.Hash) {
+		return fmt.Errorf("invalid object hash %q in snapshot", f.Hash)
+	}
+	return nil
+}
+
+// restoreSnapshotFile rewrites a file with the content of a snapshot object
+func restoreSnapshotFile(config *Config, f snapshotFile, target string) error {
+	absRemote, err := filepath.Abs(config.Remote)
+	if err != nil {
+		return err
+	}
+	content, err := readObject(filepath.Join(absRemote, HistoryDirName, historyObjectsDir), f.Hash)
+	if err != nil {
+		return err
+	}
+	mode, err := parseLinkMode(f.Mode)
+	if err != nil {
+		return fmt.Errorf("invalid mode %s: %w", f.Mode, err)
+	}
+
+	journalTouch(target)
+	if info, err := os.Lstat(target); err == nil && !info.Mode().IsRegular() {
+		return fmt.Errorf("not a regular file")
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+
+	// Write in place so that hard links to the file see the restored content
+	out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := out.Write(content); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Chmod(target, mode)
+}
+
+// loadSnapshots returns the snapshot manifests, oldest first
+func loadSnapshots(config *Config) ([]*snapshotManifest, error) {
+	absRemote, err := filepath.Abs(config.Remote)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote directory path: %w", err)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(absRemote, HistoryDirName, historySnapshotsDir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var manifests []*snapshotManifest
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok {
+			continue
+		}
+		manifest, err := loadSnapshot(config, id)
+		if err != nil {
+			return nil, err
+		}
+		manifests = append(manifests, manifest)
+	}
+	sort.SliceStable(manifests, func(i, j int) bool {
+		return manifests[i].Created.Before(manifests[j].Created)
+	})
+	return manifests, nil
+}
+
+// loadSnapshot reads the manifest of a snapshot
+func loadSnapshot(config *Config, id string) (*snapshotManifest, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) || strings.HasPrefix(id, ".") {
+		return nil, fmt.Errorf("invalid snapshot id: %s", id)
+	}
+	absRemote, err := filepath.Abs(config.Remote)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote directory path: %w", err)
+	}
+
+	path := filepath.Join(absRemote, HistoryDirName, historySnapshotsDir, id+".json")
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("snapshot not found: %s. Run 'lnkr snapshot list' to see the snapshots", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &snapshotManifest{}
+	if err := json.Unmarshal(content, manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return manifest, nil
+}
+
+// snapshotLinks returns the links recorded in a snapshot
+func snapshotLinks(manifest *snapshotManifest) []Link {
+	var links []Link
+	seen := make(map[string]struct{})
+	for _, f := range manifest.Files {
+		if _, ok := seen[f.Link]; ok {
+			continue
+		}
+		seen[f.Link] = struct{}{}
+		links = append(links, Link{Path: f.Link})
+	}
+	return links
+}
+
+func printSnapshotTable(manifests []*snapshotManifest) {
+	// Calculate max width for each column
+	const created = "2006-01-02 15:04:05"
+	maxID := len("ID")
+	maxFiles := len("Files")
+	maxSize := len("Size")
+	sizes := make([]string, len(manifests))
+	for i, m := range manifests {
+		var size int64
+		for _, f := range m.Files {
+			size += f.Size
+		}
+		sizes[i] = formatSize(size)
+		maxID = max(maxID, len(m.ID))
+		maxFiles = max(maxFiles, len(fmt.Sprint(len(m.Files))))
+		maxSize = max(maxSize, len(sizes[i]))
+	}
+
+	// Print header
+	header := fmt.Sprintf("%-*s  %-*s  %*s  %*s  %s", maxID, "ID", len(created), "Created", maxFiles, "Files", maxSize, "Size", "Reason")
+	fmt.Println(header)
+	fmt.Println(strings.Repeat("-", len(header)))
+
+	for i, m := range manifests {
+		fmt.Printf("%-*s  %-*s  %*d  %*s  %s\n", maxID, m.ID, len(created), m.Created.Local().Format(created), maxFiles, len(m.Files), maxSize, sizes[i], m.Reason)
+	}
+}
@@ -0,0 +1,49 @@
+package lnkr
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidateSnapshotFile(t *testing.T) {
+	hash := contentHash([]byte("content"))
+	tests := []struct {
+		name    string
+		file    snapshotFile
+		wantErr bool
+	}{
+		{"remote file", snapshotFile{Side: snapshotSideRemote, Path: "dir/file.txt", Hash: hash}, false},
+		{"local file", snapshotFile{Side: snapshotSideLocal, Path: "file.txt", Hash: hash}, false},
+		{"unknown side", snapshotFile{Side: "other", Path: "file.txt", Hash: hash}, true},
+		{"parent path", snapshotFile{Side: snapshotSideLocal, Path: "../../escaped.txt", Hash: hash}, true},
+		{"absolute path", snapshotFile{Side: snapshotSideLocal, Path: "/etc/passwd", Hash: hash}, true},
+		{"short hash", snapshotFile{Side: snapshotSideLocal, Path: "file.txt", Hash: "a"}, true},
+		{"upper case hash", snapshotFile{Side: snapshotSideLocal, Path: "file.txt", Hash: "ED7002B439E9AC845F22357D822BAC1444730FBDB6016D3EC9432297B9EC9F73"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateSnapshotFile(tt.file); (err != nil) != tt.wantErr {
+				t.Errorf("validateSnapshotFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReadObjectChecksContent(t *testing.T) {
+	dir := t.TempDir()
+	hash, err := storeObject(dir, []byte("content"))
+	if err != nil {
+		t.Fatalf("storeObject: %v", err)
+	}
+	if _, err := readObject(dir, hash); err != nil {
+		t.Fatalf("readObject: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, hash[:2], hash[2:]), []byte("tampered"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readObject(dir, hash); err == nil {
+		t.Error("readObject accepted an object whose content does not match its hash")
+	}
+}
@@ -18,7 +18,8 @@ type LinkStatus struct {
 	Error      string
 }
 
-func Status() error {
+// Status prints the status of the links at or under paths, or of all links
+func Status(paths []string) error {
 	config, err := loadConfig()
 	if err != nil {
 		return fmt.Errorf("failed to load configuration: %w", err)
@@ -29,8 +30,13 @@ func Status() error {
 		return nil
 	}
 
+	links, err := selectLinks(config, paths)
+	if err != nil {
+		return err
+	}
+
 	var statuses []LinkStatus
-	for _, link := range config.Links {
+	for _, link := range links {
 		status := checkLinkStatus(link, config)
 		statuses = append(statuses, status)
 	}
@@ -111,8 +117,8 @@ func checkLinkStatus(link Link, config *Config) LinkStatus {
 	status.LocalPath = link.Path
 	status.RemotePath = filepath.Join(absRemote, link.Path)
 
-	// Check if the link path exists
-	info, err := os.Stat(status.LocalPath)
+	// Check if the link path exists (without following symbolic links)
+	info, err := os.Lstat(status.LocalPath)
 	if os.IsNotExist(err) {
 		status.Exists = false
 		status.Error = "LINK NOT FOUND"
@@ -189,6 +195,55 @@ func checkLinkStatus(link Link, config *Config) LinkStatus {
 		}
 
 		status.IsLink = true
+
+	case LinkTypeCopy:
+		// Check if the target exists
+		if _, err := os.Stat(status.RemotePath); os.IsNotExist(err) {
+			status.Error = "TARGET NOT FOUND"
+			return status
+		}
+
+		// A copy is up to date when its content matches the remote
+		same, err := sameAsRemote(link, status.LocalPath, status.RemotePath)
+		if err != nil {
+			status.Error = fmt.Sprintf("Cannot compare content: %v", err)
+			return status
+		}
+		if !same {
+			if link.Template {
+				status.Error = "Content differs from rendered template"
+			} else {
+				status.Error = "Content differs from target"
+			}
+			return status
+		}
+
+		status.IsLink = true
+
+	case LinkTypeEncrypted:
+		if _, err := os.Stat(status.RemotePath); os.IsNotExist(err) {
+			status.Error = "TARGET NOT FOUND"
+			return status
+		}
+
+		// The decrypted copy is current when it matches the decrypted remote
+		same, err := sameAsRemote(link, status.LocalPath, status.RemotePath)
+		if err != nil {
+			status.Error = fmt.Sprintf("Cannot decrypt: %v", err)
+			return status
+		}
+		if !same {
+			status.Error = encryptedStatus(status.LocalPath, status.RemotePath)
+			return status
+		}
+
+		status.IsLink = true
+	}
+
+	// A link in place must also have the configured mode and owner
+	if problem := checkLinkOptions(link, status.LocalPath); problem != "" {
+		status.IsLink = false
+		status.Error = problem
 	}
 
 	return status
@@ -0,0 +1,162 @@
+package lnkr
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// SyncOptions controls the behavior of Sync
+type SyncOptions struct {
+	// Decrypt the remote over the local files instead of encrypting local edits
+	FromRemote bool
+	// Overwrite a side that changed after the other one
+	Force bool
+}
+
+// Sync encrypts the local edits of encrypted links at or under paths (or of all
+// encrypted links) into the remote, or with FromRemote decrypts the remote over
+// the local files
+func Sync(paths []string, opts SyncOptions) error {
+	config, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if config.Remote == "" {
+		return fmt.Errorf("remote directory not configured. Run 'lnkr init --remote <path>' first")
+	}
+
+	absRemote, err := filepath.Abs(config.Remote)
+	if err != nil {
+		return fmt.Errorf("invalid remote directory path: %w", err)
+	}
+
+	links, err := selectLinks(config, paths)
+	if err != nil {
+		return err
+	}
+
+	// A forced or --from-remote sync may overwrite the only copy of an edit, so a
+	// snapshot is taken before the first file is overwritten
+	snapshotTaken := !opts.Force && !opts.FromRemote
+	beforeOverwrite := func() error {
+		if snapshotTaken {
+			return nil
+		}
+		reason := "before sync --force"
+		if opts.FromRemote {
+			reason = "before sync --from-remote"
+		}
+		if _, err := autoSnapshot(config, reason); err != nil {
+			return err
+		}
+		snapshotTaken = true
+		return nil
+	}
+
+	synced, skipped, failed := 0, 0, 0
+	encryptedLinks := 0
+	for _, link := range links {
+		if link.Type != LinkTypeEncrypted {
+			continue
+		}
+		encryptedLinks++
+
+		localAbs := filepath.Join(config.Local, link.Path)
+		remoteAbs := filepath.Join(absRemote, link.Path)
+		done, err := syncEncrypted(link, localAbs, remoteAbs, opts, beforeOverwrite)
+		switch {
+		case err != nil:
+			fmt.Printf("Error syncing %s: %v\n", link.Path, err)
+			failed++
+		case done:
+			synced++
+		default:
+			skipped++
+		}
+	}
+
+	if encryptedLinks == 0 {
+		fmt.Println("No encrypted links to sync.")
+		return nil
+	}
+	fmt.Printf("Summary: %d synced, %d unchanged or skipped, %d failed\n", synced, skipped, failed)
+	if failed > 0 {
+		return fmt.Errorf("failed to sync %d link(s)", failed)
+	}
+	return nil
+}
+
+// syncEncrypted syncs one encrypted link and reports whether a file was written.
+// beforeOverwrite is called before a file that differs from the other side is
+// overwritten.
+func syncEncrypted(link Link, localAbs, remoteAbs string, opts SyncOptions, beforeOverwrite func() error) (bool, error) {
+	localInfo, localErr := os.Stat(localAbs)
+	remoteInfo, remoteErr := os.Stat(remoteAbs)
+
+	switch {
+	case localErr != nil && !os.IsNotExist(localErr):
+		return false, localErr
+	case remoteErr != nil && !os.IsNotExist(remoteErr):
+		return false, remoteErr
+	case os.IsNotExist(localErr) && os.IsNotExist(remoteErr):
+		return false, fmt.Errorf("missing on both sides")
+	case os.IsNotExist(localErr):
+		if !opts.FromRemote {
+			fmt.Printf("Not decrypted locally, skipping: %s. Run 'lnkr link' to create it\n", localAbs)
+			return false, nil
+		}
+	case os.IsNotExist(remoteErr):
+		if opts.FromRemote {
+			fmt.Printf("Only in local, skipping: %s\n", localAbs)
+			return false, nil
+		}
+	default:
+		same, err := sameAsRemote(link, localAbs, remoteAbs)
+		if err != nil {
+			return false, err
+		}
+		if same {
+			return false, nil
+		}
+
+		// Refuse to overwrite a side that changed last unless forced
+		remoteNewer := remoteInfo.ModTime().After(localInfo.ModTime())
+		if !opts.Force && !opts.FromRemote && remoteNewer {
+			fmt.Printf("Remote changed after %s, skipping. Compare with 'lnkr diff %s', then use --from-remote or --force\n", localAbs, link.Path)
+			return false, nil
+		}
+		if !opts.Force && opts.FromRemote && !remoteNewer {
+			fmt.Printf("%s has local edits, skipping. Compare with 'lnkr diff %s', then sync without --from-remote or use --force\n", localAbs, link.Path)
+			return false, nil
+		}
+		if err := beforeOverwrite(); err != nil {
+			return false, err
+		}
+	}
+
+	if !opts.FromRemote {
+		if err := encryptFile(localAbs, remoteAbs); err != nil {
+			return false, fmt.Errorf("failed to encrypt: %w", err)
+		}
+		fmt.Printf("Encrypted: %s -> %s\n", localAbs, remoteAbs)
+		return true, nil
+	}
+
+	plain, err := decryptFile(remoteAbs)
+	if err != nil {
+		return false, err
+	}
+	journalTouchDecrypted(localAbs)
+	if err := os.MkdirAll(filepath.Dir(localAbs), 0755); err != nil {
+		return false, fmt.Errorf("failed to create local directory: %w", err)
+	}
+	if err := writeFileAtomic(localAbs, plain, 0600); err != nil {
+		return false, fmt.Errorf("failed to write decrypted file: %w", err)
+	}
+	if err := applyLinkOptions(link, localAbs); err != nil {
+		return false, err
+	}
+	fmt.Printf("Decrypted: %s -> %s\n", remoteAbs, localAbs)
+	return true, nil
+}
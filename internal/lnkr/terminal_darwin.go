@@ -0,0 +1,11 @@
+//go:build darwin
+
+package lnkr
+
+import "syscall"
+
+// ioctl requests reading and writing terminal attributes
+const (
+	ioctlGetTermios = syscall.TIOCGETA
+	ioctlSetTermios = syscall.TIOCSETA
+)
@@ -0,0 +1,20 @@
+//go:build !linux && !darwin
+
+package lnkr
+
+import "fmt"
+
+// rawTerminal is not available on this platform
+type rawTerminal struct{}
+
+func makeRaw(fd int) (*rawTerminal, error) {
+	return nil, fmt.Errorf("interactive mode is not supported on this platform")
+}
+
+func (t *rawTerminal) restore() error {
+	return nil
+}
+
+func terminalSize(fd int) (int, int, error) {
+	return 0, 0, fmt.Errorf("terminal size is not available on this platform")
+}
@@ -0,0 +1,59 @@
+//go:build linux || darwin
+
+package lnkr
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// rawTerminal is a terminal switched to raw mode, with the attributes to restore
+type rawTerminal struct {
+	fd    int
+	saved syscall.Termios
+}
+
+// makeRaw switches a terminal to raw mode: input is read byte by byte without
+// echo or signals, while output processing is kept
+func makeRaw(fd int) (*rawTerminal, error) {
+	var termios syscall.Termios
+	if err := ioctl(fd, ioctlGetTermios, unsafe.Pointer(&termios)); err != nil {
+		return nil, err
+	}
+
+	raw := termios
+	raw.Iflag &^= syscall.IGNBRK | syscall.BRKINT | syscall.PARMRK | syscall.ISTRIP | syscall.INLCR | syscall.IGNCR | syscall.ICRNL | syscall.IXON
+	raw.Lflag &^= syscall.ECHO | syscall.ECHONL | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	raw.Cflag &^= syscall.CSIZE | syscall.PARENB
+	raw.Cflag |= syscall.CS8
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+	if err := ioctl(fd, ioctlSetTermios, unsafe.Pointer(&raw)); err != nil {
+		return nil, err
+	}
+
+	return &rawTerminal{fd: fd, saved: termios}, nil
+}
+
+// restore puts the terminal back into the mode it had before makeRaw
+func (t *rawTerminal) restore() error {
+	return ioctl(t.fd, ioctlSetTermios, unsafe.Pointer(&t.saved))
+}
+
+// terminalSize returns the number of rows and columns of a terminal
+func terminalSize(fd int) (int, int, error) {
+	var size struct {
+		rows, cols, xpixel, ypixel uint16
+	}
+	if err := ioctl(fd, syscall.TIOCGWINSZ, unsafe.Pointer(&size)); err != nil {
+		return 0, 0, err
+	}
+	return int(size.rows), int(size.cols), nil
+}
+
+func ioctl(fd int, request uintptr, arg unsafe.Pointer) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), request, uintptr(arg)); errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -6,7 +6,10 @@ import (
 	"path/filepath"
 )
 
-func Unlink() error {
+// Unlink removes the links at or under paths, or all links, from the filesystem.
+// Copies whose content differs from the remote, and decrypted files with edits
+// that are not encrypted yet, are kept unless force is set.
+func Unlink(paths []string, force bool) error {
 	config, err := loadConfig()
 	if err != nil {
 		return fmt.Errorf("failed to load configuration: %w", err)
@@ -17,10 +20,50 @@ func Unlink() error {
 		return nil
 	}
 
+	links, err := selectLinks(config, paths)
+	if err != nil {
+		return err
+	}
+
+	absRemote, err := filepath.Abs(config.Remote)
+	if err != nil {
+		return fmt.Errorf("invalid remote directory path: %w", err)
+	}
+
 	// Use local directory as base for resolving link paths
 	baseDir := config.Local
 
-	for _, link := range config.Links {
+	// Local edits of a copy or a decrypted file exist nowhere else
+	drifted := make(map[string]error)
+	for _, link := range links {
+		if link.Type != LinkTypeCopy && link.Type != LinkTypeEncrypted {
+			continue
+		}
+		localAbs := filepath.Join(baseDir, link.Path)
+		if _, err := os.Lstat(localAbs); err == nil {
+			if err := checkInSync(link, localAbs, filepath.Join(absRemote, link.Path)); err != nil {
+				drifted[link.Path] = err
+			}
+		}
+	}
+	if force && len(drifted) > 0 {
+		if _, err := autoSnapshot(config, "before unlink --force"); err != nil {
+			return err
+		}
+	}
+
+	for _, link := range links {
+		if err, ok := drifted[link.Path]; ok && !force {
+			localAbs := filepath.Join(baseDir, link.Path)
+			if link.Type == LinkTypeEncrypted {
+				fmt.Printf("Kept %s: %v. Run 'lnkr sync %s' to encrypt them or use --force\n", localAbs, err, link.Path)
+			} else {
+				fmt.Printf("Kept %s: %v. Check with 'lnkr diff %s' or use --force\n", localAbs, err, link.Path)
+			}
+			continue
+		}
+
+		journalTouchLink(link, filepath.Join(baseDir, link.Path), filepath.Join(absRemote, link.Path))
 		if err := removeLinkWithBase(link, baseDir); err != nil {
 			fmt.Printf("Error removing link for %s: %v\n", link.Path, err)
 			continue
@@ -63,6 +106,16 @@ func removeLinkWithBase(link Link, baseDir string) error {
 			return fmt.Errorf("failed to remove symbolic link: %w", err)
 		}
 		fmt.Printf("Removed symbolic link: %s\n", linkAbs)
+	case LinkTypeCopy:
+		if err := os.RemoveAll(linkAbs); err != nil {
+			return fmt.Errorf("failed to remove copy: %w", err)
+		}
+		fmt.Printf("Removed copy: %s\n", linkAbs)
+	case LinkTypeEncrypted:
+		if err := os.Remove(linkAbs); err != nil {
+			return fmt.Errorf("failed to remove decrypted file: %w", err)
+		}
+		fmt.Printf("Removed decrypted file: %s\n", linkAbs)
 	default:
 		return fmt.Errorf("unknown link type: %s", link.Type)
 	}
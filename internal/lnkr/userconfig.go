@@ -0,0 +1,199 @@
+package lnkr
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// User configuration file name constant
+const UserConfigFileName = "config.toml"
+
+// Environment variable names
+const (
+	EnvRemoteRoot     = "LNKR_REMOTE_ROOT"
+	EnvRemoteDepth    = "LNKR_REMOTE_DEPTH"
+	EnvLinkType       = "LNKR_LINK_TYPE"
+	EnvGitExcludePath = "LNKR_GIT_EXCLUDE_PATH"
+	EnvRemoteLayout   = "LNKR_REMOTE_LAYOUT"
+	EnvRemoteTemplate = "LNKR_REMOTE_TEMPLATE"
+	EnvKey            = "LNKR_KEY"
+	EnvKeyFile        = "LNKR_KEY_FILE"
+)
+
+// UserConfig holds user-level defaults read from $XDG_CONFIG_HOME/lnkr/config.toml
+type UserConfig struct {
+	RemoteRoot     string   `toml:"remote_root"`
+	RemoteDepth    int      `toml:"remote_depth"`
+	RemoteLayout   string   `toml:"remote_layout"`
+	RemoteTemplate string   `toml:"remote_template"`
+	LinkType       string   `toml:"link_type"`
+	GitExcludePath string   `toml:"git_exclude_path"`
+	KeyFile        string   `toml:"key_file"`
+	ScanSecrets    bool     `toml:"scan_secrets"`
+	Ignore         []string `toml:"ignore"`
+}
+
+// UserConfigDir returns $XDG_CONFIG_HOME/lnkr, falling back to $HOME/.config/lnkr
+func UserConfigDir() (string, error) {
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" && filepath.IsAbs(xdg) {
+		return filepath.Join(xdg, "lnkr"), nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".config", "lnkr"), nil
+}
+
+// UserStateDir returns $XDG_STATE_HOME/lnkr, falling back to $HOME/.local/state/lnkr
+func UserStateDir() (string, error) {
+	if xdg := os.Getenv("XDG_STATE_HOME"); xdg != "" && filepath.IsAbs(xdg) {
+		return filepath.Join(xdg, "lnkr"), nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".local", "state", "lnkr"), nil
+}
+
+// UserConfigPath returns the path of the user configuration file
+func UserConfigPath() (string, error) {
+	dir, err := UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, UserConfigFileName), nil
+}
+
+// LoadUserConfig loads the user configuration file, returning empty defaults if it does not exist
+func LoadUserConfig() (*UserConfig, error) {
+	userConfig := &UserConfig{}
+
+	path, err := UserConfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return userConfig, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := toml.Decode(string(content), userConfig); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+
+	return userConfig, nil
+}
+
+// ResolveRemoteRoot returns the base directory for remote paths.
+// Precedence: LNKR_REMOTE_ROOT > remote_root in user config > $XDG_CONFIG_HOME/lnkr
+func ResolveRemoteRoot(userConfig *UserConfig) (string, error) {
+	if root := os.Getenv(EnvRemoteRoot); root != "" {
+		return root, nil
+	}
+
+	if userConfig.RemoteRoot != "" {
+		return expandHome(userConfig.RemoteRoot)
+	}
+
+	return UserConfigDir()
+}
+
+// ResolveRemoteDepth returns the number of directory levels used for the default remote path.
+// Precedence: LNKR_REMOTE_DEPTH > remote_depth in user config > DefaultRemoteDepth
+func ResolveRemoteDepth(userConfig *UserConfig) (int, error) {
+	if depthStr := os.Getenv(EnvRemoteDepth); depthStr != "" {
+		depth, err := parseRemoteDepth(depthStr)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s: %w", EnvRemoteDepth, err)
+		}
+		return depth, nil
+	}
+
+	if userConfig.RemoteDepth < 0 {
+		return 0, fmt.Errorf("invalid remote_depth in user configuration: %d (must be a positive integer)", userConfig.RemoteDepth)
+	}
+	if userConfig.RemoteDepth > 0 {
+		return userConfig.RemoteDepth, nil
+	}
+
+	return DefaultRemoteDepth, nil
+}
+
+// parseRemoteDepth parses a remote depth value, which must be a positive integer
+func parseRemoteDepth(value string) (int, error) {
+	depth, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || depth <= 0 {
+		return 0, fmt.Errorf("%q is not a positive integer", value)
+	}
+	return depth, nil
+}
+
+// ResolveLinkType returns the default link type for new links.
+// Precedence: LNKR_LINK_TYPE > link_type in user config > hard
+func ResolveLinkType(userConfig *UserConfig) string {
+	if linkType := os.Getenv(EnvLinkType); linkType != "" {
+		return linkType
+	}
+
+	if userConfig.LinkType != "" {
+		return userConfig.LinkType
+	}
+
+	return LinkTypeHard
+}
+
+// ResolveGitExcludePath returns the default git exclude path for new projects.
+// Precedence: LNKR_GIT_EXCLUDE_PATH > git_exclude_path in user config > .git/info/exclude
+func ResolveGitExcludePath(userConfig *UserConfig) string {
+	if path := os.Getenv(EnvGitExcludePath); path != "" {
+		return path
+	}
+
+	if userConfig.GitExcludePath != "" {
+		return userConfig.GitExcludePath
+	}
+
+	return GitExcludePath
+}
+
+// isIgnored reports whether a relative path matches one of the ignore patterns.
+// A pattern matches either the whole path or any single component of it.
+func isIgnored(relPath string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := filepath.Match(pattern, relPath); matched {
+			return true
+		}
+		for _, component := range strings.Split(relPath, string(os.PathSeparator)) {
+			if matched, _ := filepath.Match(pattern, component); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// expandHome expands a leading ~ to the user's home directory
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, strings.TrimPrefix(path, "~")), nil
+}